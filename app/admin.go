@@ -0,0 +1,311 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/lsytj0413/nuwa"
+	"github.com/lsytj0413/nuwa/logger"
+	"github.com/lsytj0413/nuwa/property"
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+const (
+	// AdminServerBeanName is the bean name of AdminServer registered by AdminBean
+	AdminServerBeanName = "nuwa.adminServer"
+
+	// AdminAddressPropertyName is the property of address which the AdminServer listen on
+	AdminAddressPropertyName = "nuwa.admin.address"
+
+	// AdminShutdownEnabledPropertyName is the property which enable the /shutdown endpoint,
+	// it's disabled by default.
+	AdminShutdownEnabledPropertyName = "nuwa.admin.shutdown.enabled"
+
+	// DefaultAdminAddress is the default address of AdminServer
+	DefaultAdminAddress = "127.0.0.1:8081"
+)
+
+// ApplicationAware is to be implemented by beans that want to access the Application, the
+// Application is injected before the ContextRefreshed event.
+type ApplicationAware interface {
+	SetApplication(a Application)
+}
+
+// BeanInfo is the description of bean definition served by /beans
+type BeanInfo struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Scope string `json:"scope"`
+	// Active is true if the bean definition matches the profiles and conditions
+	Active bool `json:"active"`
+	// Dependencies is the names of beans autowired to the fields
+	Dependencies []string `json:"dependencies"`
+	Aliases      []string `json:"aliases"`
+}
+
+// ConfigEntry is the effective property served by /config, the secret value is masked
+type ConfigEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// NewAdminHandler return the http.Handler which serve the introspection endpoints of a:
+//  1. GET /health: the HealthReport, the status code is 503 if the application is not ready
+//  2. GET /health/liveness and /health/readiness: the liveness or readiness only
+//  3. GET /beans: the BeanInfo of all bean definitions in registration order
+//  4. GET /config: the ConfigEntry of effective properties in ascending order of key
+//  5. POST /shutdown: shutdown the application if AdminShutdownEnabledPropertyName is true
+func NewAdminHandler(a Application) http.Handler {
+	h := &adminHandler{
+		app: a,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", h.health)
+	mux.HandleFunc("/health/liveness", h.health)
+	mux.HandleFunc("/health/readiness", h.health)
+	mux.HandleFunc("/beans", h.beans)
+	mux.HandleFunc("/config", h.config)
+	mux.HandleFunc("/shutdown", h.shutdown)
+	return mux
+}
+
+type adminHandler struct {
+	app Application
+}
+
+func (h *adminHandler) health(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	report := h.app.Health(r.Context())
+	var body interface{} = report
+	status := StatusUp
+	switch r.URL.Path {
+	case "/health/liveness":
+		status = report.Liveness
+		body = map[string]Status{"status": status}
+	case "/health/readiness":
+		status = report.Readiness
+		body = map[string]Status{"status": status}
+	default:
+		if report.Liveness != StatusUp || report.Readiness != StatusUp {
+			status = StatusDown
+		}
+	}
+
+	code := http.StatusOK
+	if status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, body)
+}
+
+func (h *adminHandler) beans(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	active := map[string]bool{}
+	for _, evaluation := range h.app.ConditionReport() {
+		active[evaluation.BeanName] = evaluation.Match
+	}
+
+	infos := []BeanInfo{}
+	for _, name := range h.app.GetBeanDefinitionNames() {
+		beanDefinition, err := h.app.GetBeanDefinition(name)
+		if err != nil {
+			continue
+		}
+
+		info := BeanInfo{
+			Name:         name,
+			Scope:        beanDefinition.Scope(),
+			Active:       active[name],
+			Dependencies: []string{},
+			Aliases:      h.app.GetAliases(name),
+		}
+		if beanDefinition.Type() != nil {
+			info.Type = beanDefinition.Type().String()
+		}
+		for _, fd := range beanDefinition.FieldDescriptors() {
+			if fd.Bean != nil {
+				info.Dependencies = append(info.Dependencies, fd.Bean.Name)
+			}
+		}
+		if info.Aliases == nil {
+			info.Aliases = []string{}
+		}
+		sort.Strings(info.Aliases)
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+func (h *adminHandler) config(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	entries := []ConfigEntry{}
+	for _, entry := range property.Entries(h.app) {
+		entries = append(entries, ConfigEntry{
+			Key:    entry.Key,
+			Value:  entry.Value,
+			Origin: entry.Origin.String(),
+		})
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (h *adminHandler) shutdown(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	enabled := false
+	err := h.app.Retrive(AdminShutdownEnabledPropertyName, &enabled)
+	if err != nil && !xerrors.Is(err, xerrors.ErrNotFound) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return
+	}
+	if !enabled {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "The shutdown endpoint is disabled"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "Shutting down"})
+	// NOTE: the Shutdown called in goroutine cannot report the caller, so the reason is
+	// passed explicitly if the application supports it
+	reason := fmt.Sprintf("Shutdown from admin endpoint %v", r.RemoteAddr)
+	if s, ok := h.app.(reasonShutdowner); ok {
+		go s.shutdownWithMessage(reason)
+		return
+	}
+	logger.Default().Info(reason)
+	go h.app.Shutdown()
+}
+
+// reasonShutdowner is implemented by the Application which can shutdown with the reason
+type reasonShutdowner interface {
+	shutdownWithMessage(msg string)
+}
+
+// allowMethod write 405 and return false if the method of r is not method
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+
+	w.Header().Set("Allow", method)
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "Method not allowed"})
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(body)
+	if err != nil {
+		logger.Default().Warn("Cannot write admin response", "err", err)
+	}
+}
+
+// AdminBean return the bean of AdminServer, eg: app.New(app.WithBeans(app.AdminBean())).
+func AdminBean() Bean {
+	return Bean{
+		Name: AdminServerBeanName,
+		Definition: (&nuwa.BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*AdminServer)(nil)),
+		}).SetScope(nuwa.ScopeSingleton),
+	}
+}
+
+// AdminServer is the Lifecycle bean which serve the handler of NewAdminHandler over HTTP,
+// it listen on the address of property AdminAddressPropertyName, or DefaultAdminAddress.
+type AdminServer struct {
+	app      Application
+	server   *http.Server
+	listener net.Listener
+	lock     sync.Mutex
+}
+
+func (s *AdminServer) SetApplication(a Application) {
+	s.app = a
+}
+
+func (s *AdminServer) Start(ctx context.Context) error {
+	if s.app == nil {
+		return xerrors.Errorf("Cannot start admin server, the application is not injected")
+	}
+
+	addr, err := s.app.Get(AdminAddressPropertyName)
+	if err != nil {
+		if !xerrors.Is(err, xerrors.ErrNotFound) {
+			return err
+		}
+		addr = DefaultAdminAddress
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return xerrors.Wrapf(err, "Cannot listen admin server on '%v'", addr)
+	}
+	server := &http.Server{
+		Handler: NewAdminHandler(s.app),
+	}
+
+	s.lock.Lock()
+	s.server, s.listener = server, l
+	s.lock.Unlock()
+
+	logger.Default().Info("Admin server started", "address", l.Addr().String())
+	go func() {
+		err := server.Serve(l)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Default().Error("Admin server failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (s *AdminServer) Stop(ctx context.Context) error {
+	s.lock.Lock()
+	server := s.server
+	s.server, s.listener = nil, nil
+	s.lock.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+func (s *AdminServer) IsRunning() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.server != nil
+}
+
+// Addr return the address which the server is listening on, or "" if it's not running
+func (s *AdminServer) Addr() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa"
+)
+
+// dependentBean is the bean definition with the bean field descriptors
+type dependentBean struct {
+	*nuwa.BeanDefinitionImpl
+	dependencies []string
+}
+
+func (b *dependentBean) FieldDescriptors() []nuwa.FieldDescriptor {
+	ret := []nuwa.FieldDescriptor{}
+	for i, name := range b.dependencies {
+		ret = append(ret, nuwa.FieldDescriptor{
+			FieldIndex: i,
+			Bean: &nuwa.BeanFieldDescriptor{
+				Name: name,
+			},
+		})
+	}
+	return ret
+}
+
+// reasonRecorder record the reason when the shutdown is started
+type reasonRecorder struct {
+	reasons chan string
+}
+
+func (r *reasonRecorder) OnEvent(event interface{}) {
+	if e, ok := event.(ShutdownStarted); ok {
+		r.reasons <- e.Reason
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	g.Expect(a.ParseArgs([]string{"--db.host=localhost", "--db.password=secret", "--db.key=ENC(abc)", "--db.url=pg://${db.password}@${db.host}"})).ToNot(HaveOccurred())
+	g.Expect(a.RegisterBeanDefinition("db", (&nuwa.BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*indicator)(nil)),
+	}).SetScope(nuwa.ScopeSingleton))).ToNot(HaveOccurred())
+	g.Expect(a.RegisterBeanDefinition("runner", &dependentBean{
+		BeanDefinitionImpl: (&nuwa.BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*blockingRunner)(nil)),
+		}).SetScope(nuwa.ScopePrototype).SetProfiles("prod"),
+		dependencies: []string{"db"},
+	})).ToNot(HaveOccurred())
+	g.Expect(a.RegisterAlias("db", "primaryDB")).ToNot(HaveOccurred())
+	g.Expect(a.RegisterAlias("db", "database")).ToNot(HaveOccurred())
+
+	type testCase struct {
+		description  string
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}
+	testCases := []testCase{
+		{
+			description:  "health",
+			method:       http.MethodGet,
+			path:         "/health",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"liveness": "UP", "readiness": "DOWN", "components": {}}`,
+		},
+		{
+			description:  "liveness",
+			method:       http.MethodGet,
+			path:         "/health/liveness",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status": "UP"}`,
+		},
+		{
+			description:  "readiness",
+			method:       http.MethodGet,
+			path:         "/health/readiness",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status": "DOWN"}`,
+		},
+		{
+			description:  "beans",
+			method:       http.MethodGet,
+			path:         "/beans",
+			expectedCode: http.StatusOK,
+			expectedBody: `[
+				{"name": "db", "type": "*app.indicator", "scope": "singleton", "active": true, "dependencies": [], "aliases": ["database", "primaryDB"]},
+				{"name": "runner", "type": "*app.blockingRunner", "scope": "prototype", "active": false, "dependencies": ["db"], "aliases": []}
+			]`,
+		},
+		{
+			description:  "config",
+			method:       http.MethodGet,
+			path:         "/config",
+			expectedCode: http.StatusOK,
+			expectedBody: `[
+				{"key": "db.host", "value": "localhost", "origin": "args --db.host=localhost"},
+				{"key": "db.key", "value": "******", "origin": "args --db.key=ENC(abc)"},
+				{"key": "db.password", "value": "******", "origin": "args --db.password=******"},
+				{"key": "db.url", "value": "pg://${db.password}@${db.host}", "origin": "args --db.url=pg://${db.password}@${db.host}"}
+			]`,
+		},
+		{
+			description:  "shutdown disabled",
+			method:       http.MethodPost,
+			path:         "/shutdown",
+			expectedCode: http.StatusForbidden,
+			expectedBody: `{"message": "The shutdown endpoint is disabled"}`,
+		},
+		{
+			description:  "method not allowed",
+			method:       http.MethodGet,
+			path:         "/shutdown",
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: `{"message": "Method not allowed"}`,
+		},
+	}
+
+	h := NewAdminHandler(a)
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			g.Expect(w.Code).To(Equal(tc.expectedCode))
+			g.Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+			g.Expect(w.Body.String()).To(MatchJSON(tc.expectedBody))
+		})
+	}
+}
+
+func TestAdminServer(t *testing.T) {
+	g := NewWithT(t)
+
+	a, err := New(
+		WithArgs([]string{"--nuwa.admin.address=127.0.0.1:0", "--nuwa.admin.shutdown.enabled=true"}),
+		WithBeans(AdminBean()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	obj, err := a.GetBean(AdminServerBeanName)
+	g.Expect(err).ToNot(HaveOccurred())
+	s := obj.(*AdminServer)
+	recorder := registerSingleton(g, a, "recorder", &reasonRecorder{
+		reasons: make(chan string, 1),
+	}).(*reasonRecorder)
+
+	// NOTE: the idle connections kept alive will delay the shutdown of server
+	client := &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+		},
+	}
+
+	ch := runAsync(a)
+	g.Eventually(s.IsRunning, time.Second).Should(BeTrue())
+	url := "http://" + s.Addr()
+	g.Eventually(func() int {
+		resp, err := client.Get(url + "/health/readiness")
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}, time.Second).Should(Equal(http.StatusOK))
+
+	resp, err := client.Post(url+"/shutdown", "application/json", nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+	g.Expect(string(body)).To(MatchJSON(`{"message": "Shutting down"}`))
+
+	g.Eventually(recorder.reasons, time.Second).Should(Receive(HavePrefix("Shutdown from admin endpoint 127.0.0.1:")))
+	g.Eventually(ch, time.Second).Should(Receive(BeNil()))
+	g.Expect(s.IsRunning()).To(BeFalse())
+	g.Expect(s.Stop(context.Background())).ToNot(HaveOccurred())
+}
@@ -2,66 +2,629 @@ package app
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"reflect"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/lsytj0413/nuwa"
+	"github.com/lsytj0413/nuwa/logger"
+	"github.com/lsytj0413/nuwa/property"
+	"github.com/lsytj0413/nuwa/xerrors"
 )
 
 // Application is the interface for app
 type Application interface {
+	// Run start the Lifecycle beans by phase, then start all the AppRunner and ErrorAppRunner
+	// beans concurrently, and block until the application is shutdown. After shutdown, the
+	// runners are waited, the Lifecycle beans are stopped by phase in reverse and then the
+	// singletons are destroyed, each step is limited by the shutdown timeout.
+	// It return the first error returned by ErrorAppRunner, or the error of teardown.
 	Run() error
+
+	// Shutdown the application, the context of runners will been cancelled.
 	Shutdown()
 
+	// ParseArgs parse the command-line args with property.FromArgs, the parsed
+	// values will have the highest precedence.
+	ParseArgs(args []string) error
+
+	// ParseFlags define a string flag in fs for every property used by the registered
+	// bean definitions which is not defined yet, then parse the args with fs. The
+	// flags set by args will have the highest precedence.
+	ParseFlags(fs *flag.FlagSet, args []string) error
+
+	// LoadConfigFile load the properties from the config file at path, and the
+	// profile-specific files (eg: application-dev.yaml for application.yaml) of the
+	// active profiles on top of it. The missing profile-specific file is ignored.
+	// The loaded values have lower precedence than the values already loaded.
+	// The files are reloaded by Reload.
+	LoadConfigFile(path string) error
+
+	// WatchConfigFile is the same as LoadConfigFile, but the files are polled with interval
+	// and reloaded when changed. The watch is stopped when the application is shutdown.
+	WatchConfigFile(path string, interval time.Duration) error
+
+	// Reload reload all the config files loaded by LoadConfigFile and WatchConfigFile, the
+	// change events are published to the listeners. It's triggered by the reload signals.
+	Reload() error
+
+	// AddChangeListener register the listener for the changes of effective properties,
+	// and return the function which remove the listener.
+	AddChangeListener(l property.ChangeListener) (remove func())
+
+	// SetDecryptor set the Decryptor for the encrypted property values.
+	SetDecryptor(d property.Decryptor)
+
+	// AddEventListener register the listener for events, the beans implementing EventListener
+	// are registered automatically by Run.
+	AddEventListener(l EventListener)
+
+	// SetShutdownTimeout set the timeout of waiting runners and destroying singletons
+	// when the application is shutdown, the default is DefaultShutdownTimeout.
+	SetShutdownTimeout(timeout time.Duration)
+
+	// SetShutdownSignals set the signals which trigger the shutdown, the default is
+	// os.Interrupt and syscall.SIGTERM. If forceQuit is true, the process will exit with
+	// ExitCodeForceQuit immediately when the signal is received again during shutdown.
+	SetShutdownSignals(forceQuit bool, sigs ...os.Signal)
+
+	// SetReloadSignals set the signals which trigger Reload, the default is syscall.SIGHUP.
+	SetReloadSignals(sigs ...os.Signal)
+
+	// ExitCode return the first non-zero exit code of the ExitCodeGenerator beans, which
+	// are collected when the application is shutdown.
+	ExitCode() int
+
+	// Health return the liveness and readiness of application aggregated from the
+	// HealthIndicator beans, the readiness is down as soon as the shutdown is started.
+	Health(ctx context.Context) HealthReport
+
+	EventPublisher
 	nuwa.BeanFactory
 }
 
 // AppRunner is the interface for runner
 type AppRunner interface {
-	// Run will been called when the application is ready.
+	// Run will been called in it's own goroutine when the application is ready, the ctx
+	// is cancelled when the application is shutdown.
 	Run(ctx context.Context)
 }
 
+// ErrorAppRunner is the runner which can report failure, the application will been
+// shutdown if it return non-nil error, and the error is returned by Application.Run.
+type ErrorAppRunner interface {
+	// Run will been called in it's own goroutine when the application is ready, the ctx
+	// is cancelled when the application is shutdown.
+	Run(ctx context.Context) error
+}
+
+// ExitCodeGenerator is to be implemented by beans or errors which determine the exit code
+// of process, see Main.
+type ExitCodeGenerator interface {
+	// ExitCode return the exit code, the zero is ignored
+	ExitCode() int
+}
+
+const (
+	// DefaultShutdownTimeout is the default timeout of shutdown
+	DefaultShutdownTimeout = 30 * time.Second
+
+	// ExitCodeForceQuit is the exit code when the shutdown signal is received twice
+	ExitCodeForceQuit = 130
+)
+
+// Main run the application and return the exit code of process, eg:
+//
+//	func main() {
+//		os.Exit(app.Main(ap))
+//	}
+//
+// The exit code is determined by:
+//  1. the error returned by Run: the code of ExitCodeGenerator in the error chain, or 1
+//  2. the Application.ExitCode if Run return nil
+//
+// The error returned by Run is written to stderr rather than the logger.Default, which
+// is no-op unless it's set, so the process will not exit with failure silently.
+func Main(a Application) int {
+	err := a.Run()
+	if err == nil {
+		return a.ExitCode()
+	}
+
+	// NOTE: Main is the only place which write to stderr directly, see the doc above
+	fmt.Fprintf(os.Stderr, "Application run failed: %v\n", err)
+	var g ExitCodeGenerator
+	if xerrors.As(err, &g) && g.ExitCode() != 0 {
+		return g.ExitCode()
+	}
+	return 1
+}
+
 type nuwaApplication struct {
-	exitChan chan struct{}
+	exitChan        chan struct{}
+	ctx             context.Context
+	cancel          context.CancelFunc
+	properties      property.CompositeProperties
+	shutdownTimeout time.Duration
+
+	// err is the first error returned by runners
+	err error
+	// running is the count of running runners by the runner type
+	running    map[string]int
+	runners    sync.WaitGroup
+	lifecycles []Lifecycle
+	events     eventMulticaster
+	health     healthState
+	exitCode   int
+
+	shutdownSignals []os.Signal
+	reloadSignals   []os.Signal
+	forceQuit       bool
+	// exit is called to force quit, it's os.Exit except in tests
+	exit func(code int)
+
+	watchers []property.WatchedProperties
+	lock     sync.Mutex
 
 	nuwa.BeanFactory
 }
 
 // NewApplication return the application
 func NewApplication() Application {
-	return &nuwaApplication{
-		exitChan:    make(chan struct{}),
-		BeanFactory: nuwa.NewBeanFactory(),
+	properties := property.NewCompositeProperties(property.NewProperties())
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &nuwaApplication{
+		exitChan:        make(chan struct{}),
+		ctx:             ctx,
+		cancel:          cancel,
+		properties:      properties,
+		shutdownTimeout: DefaultShutdownTimeout,
+		running:         make(map[string]int),
+		shutdownSignals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+		reloadSignals:   []os.Signal{syscall.SIGHUP},
+		forceQuit:       true,
+		exit:            os.Exit,
+		BeanFactory:     nuwa.NewBeanFactoryWithProperties(properties),
+	}
+	a.events.add(&a.health)
+	return a
+}
+
+// Decryptor return the Decryptor of properties, the child factory of application decrypt
+// the values of application with it
+func (a *nuwaApplication) Decryptor() property.Decryptor {
+	return a.properties.Decryptor()
+}
+
+func (a *nuwaApplication) ParseArgs(args []string) error {
+	p, err := property.FromArgs(args)
+	if err != nil {
+		return err
+	}
+
+	a.properties.AddFirst(p)
+	return nil
+}
+
+func (a *nuwaApplication) ParseFlags(fs *flag.FlagSet, args []string) error {
+	for _, beanDefinition := range a.GetAllBeanDefinition() {
+		for _, fd := range beanDefinition.FieldDescriptors() {
+			if fd.Property == nil || fs.Lookup(fd.Property.Name) != nil {
+				continue
+			}
+
+			fs.String(fd.Property.Name, "", fmt.Sprintf("override the property '%v'", fd.Property.Name))
+		}
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	a.properties.AddFirst(property.FromFlagSet(fs))
+	return nil
+}
+
+func (a *nuwaApplication) LoadConfigFile(path string) error {
+	return a.WatchConfigFile(path, 0)
+}
+
+func (a *nuwaApplication) WatchConfigFile(path string, interval time.Duration) error {
+	return a.loadConfigFile(path, func(path string) (property.Properties, error) {
+		w, err := property.WatchFile(path, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		a.watchers = append(a.watchers, w)
+		return w, nil
+	})
+}
+
+func (a *nuwaApplication) Reload() error {
+	a.lock.Lock()
+	watchers := append([]property.WatchedProperties{}, a.watchers...)
+	a.lock.Unlock()
+
+	errs := []error{}
+	for _, w := range watchers {
+		errs = append(errs, w.Reload())
+	}
+	return joinErrors(errs)
+}
+
+func (a *nuwaApplication) AddChangeListener(l property.ChangeListener) func() {
+	return a.properties.AddChangeListener(l)
+}
+
+func (a *nuwaApplication) SetDecryptor(d property.Decryptor) {
+	a.properties.SetDecryptor(d)
+}
+
+func (a *nuwaApplication) SetShutdownTimeout(timeout time.Duration) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.shutdownTimeout = timeout
+}
+
+func (a *nuwaApplication) SetShutdownSignals(forceQuit bool, sigs ...os.Signal) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.forceQuit = forceQuit
+	a.shutdownSignals = sigs
+}
+
+func (a *nuwaApplication) SetReloadSignals(sigs ...os.Signal) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.reloadSignals = sigs
+}
+
+func (a *nuwaApplication) Health(ctx context.Context) HealthReport {
+	return a.health.report(ctx)
+}
+
+func (a *nuwaApplication) ExitCode() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.exitCode
+}
+
+func (a *nuwaApplication) loadConfigFile(path string, load func(path string) (property.Properties, error)) error {
+	base, err := load(path)
+	if err != nil {
+		return err
+	}
+
+	// The active profiles may be specified by the base file, so resolve it with
+	// the base file as the lowest precedence.
+	profiles := a.ActiveProfiles()
+	if len(profiles) == 0 {
+		v, err := base.Get(nuwa.ProfilesActivePropertyName)
+		if err == nil {
+			profiles = nuwa.ParseProfiles(v)
+			a.SetActiveProfiles(profiles...)
+		}
+	}
+
+	file := property.NewCompositeProperties(base)
+	for _, profile := range profiles {
+		profilePath := property.ProfileFileName(path, profile)
+		if _, err := os.Stat(profilePath); os.IsNotExist(err) {
+			continue
+		}
+
+		p, err := load(profilePath)
+		if err != nil {
+			return err
+		}
+		file.AddFirst(p)
 	}
+
+	a.properties.AddLast(file)
+	return nil
 }
 
 func (a *nuwaApplication) Run() error {
-	go func() {
-		ch := make(chan os.Signal, 1)
-		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
-		sig := <-ch
-		a.shutdownWithMessage(fmt.Sprintf("Receive signal: %v", sig))
-	}()
+	done := make(chan struct{})
+	defer close(done)
+	a.handleSignals(done)
+	start := time.Now()
 
-	// Prepare the application
+	// Prepare the application, each bean is resolved once for all the roles it implements
+	beans := &beanCache{
+		factory: a,
+		beans:   map[string]interface{}{},
+	}
 	runners := []AppRunner{}
-	err := a.RetriveBeans(&runners)
+	errRunners := []ErrorAppRunner{}
+	a.lifecycles = nil
+	listeners := []EventListener{}
+	awares := []EventPublisherAware{}
+	appAwares := []ApplicationAware{}
+	indicators := map[string]HealthIndicator{}
+	generators := []ExitCodeGenerator{}
+	roles := []struct {
+		typ reflect.Type
+		add func(name string, b interface{})
+	}{
+		{appRunnerType, func(name string, b interface{}) { runners = append(runners, b.(AppRunner)) }},
+		{errorAppRunnerType, func(name string, b interface{}) { errRunners = append(errRunners, b.(ErrorAppRunner)) }},
+		{lifecycleType, func(name string, b interface{}) { a.lifecycles = append(a.lifecycles, b.(Lifecycle)) }},
+		{eventListenerType, func(name string, b interface{}) { listeners = append(listeners, b.(EventListener)) }},
+		{eventPublisherAwareType, func(name string, b interface{}) { awares = append(awares, b.(EventPublisherAware)) }},
+		{applicationAwareType, func(name string, b interface{}) { appAwares = append(appAwares, b.(ApplicationAware)) }},
+		{healthIndicatorType, func(name string, b interface{}) { indicators[name] = b.(HealthIndicator) }},
+		// NOTE: the generators are retrived before shutdown, the beans which are not shared
+		// will not been created again after they are destroyed
+		{exitCodeGeneratorType, func(name string, b interface{}) { generators = append(generators, b.(ExitCodeGenerator)) }},
+	}
+	for _, role := range roles {
+		err := beans.each(role.typ, role.add)
+		if err != nil {
+			return err
+		}
+	}
+	a.events.add(listeners...)
+	for _, aware := range awares {
+		aware.SetEventPublisher(a)
+	}
+	for _, aware := range appAwares {
+		aware.SetApplication(a)
+	}
+	a.health.setIndicators(indicators)
+	a.Publish(ContextRefreshed{})
+
+	// The runners are started after all the components are started
+	err := startLifecycles(a.ctx, a.lifecycles)
 	if err != nil {
-		return err
+		a.fail(err, fmt.Sprintf("Lifecycle failed: %v", err))
+		runners, errRunners = nil, nil
+	} else {
+		a.Publish(ApplicationStarted{})
 	}
 
 	for _, r := range runners {
-		r.Run(context.TODO())
+		r := r
+		a.goRun(fmt.Sprintf("%T", r), func(ctx context.Context) error {
+			r.Run(ctx)
+			return nil
+		})
+	}
+	for _, r := range errRunners {
+		a.goRun(fmt.Sprintf("%T", r), r.Run)
+	}
+	if err == nil {
+		logger.Default().Info("Application started", "profiles", a.ActiveProfiles(), "runners", len(runners)+len(errRunners), "elapsed", time.Since(start))
+		a.Publish(ApplicationReady{})
 	}
 
 	<-a.exitChan
+	a.collectExitCode(generators)
+	stop := time.Now()
+	teardownErr := a.teardown()
+	logger.Default().Info("Application stopped", "elapsed", time.Since(stop))
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.err != nil {
+		if teardownErr != nil {
+			logger.Default().Error("Application teardown failed", "err", teardownErr)
+		}
+		return a.err
+	}
+	return teardownErr
+}
+
+// handleSignals handle the shutdown and reload signals until the done is closed
+func (a *nuwaApplication) handleSignals(done chan struct{}) {
+	a.lock.Lock()
+	shutdownSignals, reloadSignals, forceQuit := a.shutdownSignals, a.reloadSignals, a.forceQuit
+	a.lock.Unlock()
+
+	shutdownCh := make(chan os.Signal, 1)
+	if len(shutdownSignals) != 0 {
+		signal.Notify(shutdownCh, shutdownSignals...)
+	}
+	reloadCh := make(chan os.Signal, 1)
+	if len(reloadSignals) != 0 {
+		signal.Notify(reloadCh, reloadSignals...)
+	}
+
+	go func() {
+		defer signal.Stop(shutdownCh)
+		defer signal.Stop(reloadCh)
+
+		for {
+			select {
+			case sig := <-shutdownCh:
+				select {
+				case <-a.exitChan:
+					if forceQuit {
+						logger.Default().Warn("Application force quit", "signal", sig)
+						a.exit(ExitCodeForceQuit)
+						return
+					}
+				default:
+					a.shutdownWithMessage(fmt.Sprintf("Receive signal: %v", sig))
+				}
+			case sig := <-reloadCh:
+				err := a.Reload()
+				if err != nil {
+					logger.Default().Error("Application reload failed", "signal", sig, "err", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// collectExitCode record the first non-zero exit code of the generators
+func (a *nuwaApplication) collectExitCode(generators []ExitCodeGenerator) {
+	for _, g := range generators {
+		if code := g.ExitCode(); code != 0 {
+			a.lock.Lock()
+			a.exitCode = code
+			a.lock.Unlock()
+			return
+		}
+	}
+}
+
+var (
+	appRunnerType           = reflect.TypeOf((*AppRunner)(nil)).Elem()
+	errorAppRunnerType      = reflect.TypeOf((*ErrorAppRunner)(nil)).Elem()
+	lifecycleType           = reflect.TypeOf((*Lifecycle)(nil)).Elem()
+	eventListenerType       = reflect.TypeOf((*EventListener)(nil)).Elem()
+	eventPublisherAwareType = reflect.TypeOf((*EventPublisherAware)(nil)).Elem()
+	applicationAwareType    = reflect.TypeOf((*ApplicationAware)(nil)).Elem()
+	exitCodeGeneratorType   = reflect.TypeOf((*ExitCodeGenerator)(nil)).Elem()
+)
+
+// beanCache resolve each bean once, so the bean which is not shared is the same instance
+// for all the roles it implements
+type beanCache struct {
+	factory nuwa.BeanFactory
+	beans   map[string]interface{}
+}
+
+// each call add with the beans which are assignable to typ, in the order of GetBeanNamesForType
+func (c *beanCache) each(typ reflect.Type, add func(name string, b interface{})) error {
+	for _, name := range c.factory.GetBeanNamesForType(typ) {
+		b, ok := c.beans[name]
+		if !ok {
+			var err error
+			b, err = c.factory.GetBean(name)
+			if err != nil {
+				return err
+			}
+			c.beans[name] = b
+		}
+		add(name, b)
+	}
 	return nil
 }
 
+// goRun call the run of runner in goroutine, the application is shutdown if it return error
+func (a *nuwaApplication) goRun(runner string, run func(ctx context.Context) error) {
+	a.lock.Lock()
+	a.running[runner]++
+	a.lock.Unlock()
+
+	a.runners.Add(1)
+	go func() {
+		defer a.runners.Done()
+		err := run(a.ctx)
+
+		a.lock.Lock()
+		a.running[runner]--
+		if a.running[runner] == 0 {
+			delete(a.running, runner)
+		}
+		a.lock.Unlock()
+		if err != nil {
+			a.fail(err, fmt.Sprintf("Runner failed: %v", err))
+		}
+	}()
+}
+
+// fail record the err as the error of Run if it's the first one, and shutdown the application
+func (a *nuwaApplication) fail(err error, msg string) {
+	a.lock.Lock()
+	if a.err == nil {
+		a.err = err
+	}
+	a.lock.Unlock()
+
+	logger.Default().Error("Application failed", "err", err)
+	a.Publish(ApplicationFailed{
+		Err: err,
+	})
+	a.shutdownWithMessage(msg)
+}
+
+func (a *nuwaApplication) Publish(event interface{}) {
+	a.events.Publish(event)
+}
+
+func (a *nuwaApplication) AddEventListener(l EventListener) {
+	a.events.add(l)
+}
+
+// teardown wait the runners to finish, stop the Lifecycle components, and then destroy
+// the singletons, each step is limited by the shutdown timeout.
+func (a *nuwaApplication) teardown() error {
+	a.lock.Lock()
+	timeout := a.shutdownTimeout
+	a.lock.Unlock()
+
+	// All the steps share the deadline, so the shutdown is limited by the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	msgs := []string{}
+	if !waitContext(ctx, a.runners.Wait) {
+		a.lock.Lock()
+		running := make([]string, 0, len(a.running))
+		for runner := range a.running {
+			running = append(running, runner)
+		}
+		a.lock.Unlock()
+		sort.Strings(running)
+		msgs = append(msgs, fmt.Sprintf("runners %v are not finished in %v", running, timeout))
+	}
+	if !a.events.wait(ctx) {
+		msgs = append(msgs, fmt.Sprintf("async event listeners are not finished in %v", timeout))
+	}
+
+	err := stopLifecycles(ctx, a.lifecycles)
+	if err != nil {
+		msgs = append(msgs, err.Error())
+	}
+
+	err = a.DestroySingletons(ctx)
+	if err != nil {
+		msgs = append(msgs, err.Error())
+	}
+
+	if len(msgs) != 0 {
+		return xerrors.Errorf("Cannot shutdown application gracefully: %v", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// waitContext call the wait, and return false if it's not returned before ctx is done
+func waitContext(ctx context.Context, wait func()) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (a *nuwaApplication) Shutdown() {
 	pc, file, line, _ := runtime.Caller(1)
 	fn := runtime.FuncForPC(pc)
@@ -75,10 +638,26 @@ func (a *nuwaApplication) Shutdown() {
 }
 
 func (a *nuwaApplication) shutdownWithMessage(msg string) {
-	fmt.Fprintf(os.Stdout, "Application exit: %v", msg)
+	started := false
+	a.lock.Lock()
 	select {
 	case <-a.exitChan:
 	default:
 		close(a.exitChan)
+		started = true
+	}
+	watchers := a.watchers
+	a.watchers = nil
+	a.lock.Unlock()
+
+	if started {
+		logger.Default().Info("Application shutdown", "reason", msg)
+		a.Publish(ShutdownStarted{
+			Reason: msg,
+		})
+	}
+	a.cancel()
+	for _, w := range watchers {
+		w.Close()
 	}
 }
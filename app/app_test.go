@@ -0,0 +1,376 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa"
+)
+
+type blockingRunner struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (r *blockingRunner) Run(ctx context.Context) {
+	close(r.started)
+	<-ctx.Done()
+	close(r.cancelled)
+}
+
+type failingRunner struct {
+	wait chan struct{}
+	err  error
+}
+
+func (r *failingRunner) Run(ctx context.Context) error {
+	<-r.wait
+	return r.err
+}
+
+// registerSingleton register the singleton bean with the fields of bean, and return the instance
+func registerSingleton(g *WithT, a Application, name string, bean interface{}) interface{} {
+	err := a.RegisterBeanDefinition(name, (&nuwa.BeanDefinitionImpl{
+		Typ: reflect.TypeOf(bean),
+	}).SetScope(nuwa.ScopeSingleton))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	v, err := a.GetBean(name)
+	g.Expect(err).ToNot(HaveOccurred())
+	reflect.ValueOf(v).Elem().Set(reflect.ValueOf(bean).Elem())
+	return v
+}
+
+func runAsync(a Application) chan error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- a.Run()
+	}()
+	return ch
+}
+
+func TestRunWithRunnerError(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	blocking := &blockingRunner{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	failing := &failingRunner{
+		wait: blocking.started,
+		err:  errors.New("boom"),
+	}
+	registerSingleton(g, a, "blocking", blocking)
+	registerSingleton(g, a, "failing", failing)
+
+	var err error
+	g.Eventually(runAsync(a), time.Second).Should(Receive(&err))
+	g.Expect(err).To(MatchError("boom"))
+	g.Eventually(blocking.cancelled, time.Second).Should(BeClosed())
+}
+
+func TestRunWithShutdown(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	blocking := &blockingRunner{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	registerSingleton(g, a, "blocking", blocking)
+
+	ch := runAsync(a)
+	g.Eventually(blocking.started, time.Second).Should(BeClosed())
+	g.Consistently(ch).ShouldNot(Receive())
+
+	a.Shutdown()
+	var err error
+	g.Eventually(ch, time.Second).Should(Receive(&err))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Eventually(blocking.cancelled, time.Second).Should(BeClosed())
+}
+
+type stuckRunner struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *stuckRunner) Run(ctx context.Context) {
+	close(r.started)
+	<-r.release
+}
+
+type disposable struct {
+	err       error
+	destroyed bool
+}
+
+func (d *disposable) Destroy() error {
+	d.destroyed = true
+	return d.err
+}
+
+func TestRunWithShutdownTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	a.SetShutdownTimeout(50 * time.Millisecond)
+	stuck := &stuckRunner{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	defer close(stuck.release)
+	d := &disposable{
+		err: errors.New("close failed"),
+	}
+	registerSingleton(g, a, "stuck", stuck)
+	d = registerSingleton(g, a, "disposable", d).(*disposable)
+
+	ch := runAsync(a)
+	g.Eventually(stuck.started, time.Second).Should(BeClosed())
+	a.Shutdown()
+
+	// The runner exhausted the timeout, so the singletons are not destroyed
+	var err error
+	g.Eventually(ch, time.Second).Should(Receive(&err))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot shutdown application gracefully: " +
+		"runners [*app.stuckRunner] are not finished in 50ms; " +
+		"Cannot destroy singletons: beans [stuck disposable] are not destroyed: context deadline exceeded"))
+	g.Expect(d.destroyed).To(BeFalse())
+}
+
+type blockingDisposable struct {
+	release chan struct{}
+}
+
+func (d *blockingDisposable) Destroy() error {
+	<-d.release
+	return nil
+}
+
+func TestRunWithBlockingDestroy(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	a.SetShutdownTimeout(50 * time.Millisecond)
+	blocking := &blockingDisposable{
+		release: make(chan struct{}),
+	}
+	defer close(blocking.release)
+	d := registerSingleton(g, a, "disposable", &disposable{
+		err: errors.New("close failed"),
+	}).(*disposable)
+	registerSingleton(g, a, "blocking", blocking)
+
+	ch := runAsync(a)
+	a.Shutdown()
+
+	var err error
+	g.Eventually(ch, time.Second).Should(Receive(&err))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot shutdown application gracefully: " +
+		"Cannot destroy singletons: beans [disposable blocking] are not destroyed: context deadline exceeded"))
+	g.Expect(d.destroyed).To(BeFalse())
+}
+
+func TestRunWithSignals(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	g.Expect(ioutil.WriteFile(path, []byte("name: v1\n"), 0644)).ToNot(HaveOccurred())
+
+	a := NewApplication()
+	a.SetShutdownTimeout(time.Second)
+	a.SetShutdownSignals(true, syscall.SIGUSR1)
+	a.SetReloadSignals(syscall.SIGUSR2)
+	exitCh := make(chan int, 1)
+	a.(*nuwaApplication).exit = func(code int) {
+		exitCh <- code
+	}
+	g.Expect(a.LoadConfigFile(path)).ToNot(HaveOccurred())
+
+	stuck := &stuckRunner{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	defer close(stuck.release)
+	registerSingleton(g, a, "stuck", stuck)
+
+	ch := runAsync(a)
+	g.Eventually(stuck.started, time.Second).Should(BeClosed())
+
+	g.Expect(ioutil.WriteFile(path, []byte("name: v2\n"), 0644)).ToNot(HaveOccurred())
+	g.Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).ToNot(HaveOccurred())
+	g.Eventually(func() string {
+		v, _ := a.Get("name")
+		return v
+	}, time.Second).Should(Equal("v2"))
+
+	g.Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).ToNot(HaveOccurred())
+	g.Eventually(a.(*nuwaApplication).exitChan, time.Second).Should(BeClosed())
+	g.Consistently(exitCh).ShouldNot(Receive())
+
+	g.Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).ToNot(HaveOccurred())
+	g.Eventually(exitCh, time.Second).Should(Receive(Equal(ExitCodeForceQuit)))
+	g.Eventually(ch, 2*time.Second).Should(Receive())
+}
+
+// exitingRunner return the err, or shutdown the application if err is nil
+type exitingRunner struct {
+	a   Application
+	err error
+}
+
+func (r *exitingRunner) Run(ctx context.Context) error {
+	if r.err == nil {
+		r.a.Shutdown()
+	}
+	return r.err
+}
+
+// propertyExitCoder return the exit code bound from property code
+type propertyExitCoder struct {
+	Code int
+}
+
+func (e *propertyExitCoder) ExitCode() int {
+	return e.Code
+}
+
+// propertyBean is the bean definition with the property field descriptors
+type propertyBean struct {
+	*nuwa.BeanDefinitionImpl
+	properties []string
+}
+
+func (b *propertyBean) FieldDescriptors() []nuwa.FieldDescriptor {
+	ret := []nuwa.FieldDescriptor{}
+	for i, name := range b.properties {
+		ret = append(ret, nuwa.FieldDescriptor{
+			FieldIndex: i,
+			Typ:        b.Type().Elem().Field(i).Type,
+			Property: &nuwa.PropertyFieldDescriptor{
+				Name: name,
+			},
+		})
+	}
+	return ret
+}
+
+// changingRunner set the property code and shutdown the application
+type changingRunner struct {
+	a Application
+}
+
+func (r *changingRunner) Run(ctx context.Context) error {
+	err := r.a.Set("code", 5)
+	if err != nil {
+		return err
+	}
+	r.a.Shutdown()
+	return nil
+}
+
+type exitCoder struct {
+	code int
+}
+
+func (e *exitCoder) ExitCode() int {
+	return e.code
+}
+
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return "exit"
+}
+
+func (e *exitCodeError) ExitCode() int {
+	return e.code
+}
+
+func TestMainExitCode(t *testing.T) {
+	type testCase struct {
+		description string
+		beans       []interface{}
+		err         error
+		expected    int
+	}
+	testCases := []testCase{
+		{
+			description: "normal exit",
+			expected:    0,
+		},
+		{
+			description: "exit code of bean",
+			beans:       []interface{}{&exitCoder{code: 0}, &exitCoder{code: 3}},
+			expected:    3,
+		},
+		{
+			description: "runner error",
+			err:         errors.New("boom"),
+			expected:    1,
+		},
+		{
+			description: "exit code of error",
+			beans:       []interface{}{&exitCoder{code: 3}},
+			err:         &exitCodeError{code: 4},
+			expected:    4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			a := NewApplication()
+			a.SetShutdownSignals(false)
+			a.SetReloadSignals()
+			for i, bean := range tc.beans {
+				registerSingleton(g, a, fmt.Sprintf("bean%v", i), bean)
+			}
+			registerSingleton(g, a, "exiting", &exitingRunner{
+				a:   a,
+				err: tc.err,
+			})
+
+			g.Expect(Main(a)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestMainExitCodeOfPrototype(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	a.SetShutdownSignals(false)
+	a.SetReloadSignals()
+	g.Expect(a.Set("code", 3)).ToNot(HaveOccurred())
+	g.Expect(a.RegisterBeanDefinition("coder", &propertyBean{
+		BeanDefinitionImpl: (&nuwa.BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*propertyExitCoder)(nil)),
+		}).SetScope(nuwa.ScopePrototype),
+		properties: []string{"code"},
+	})).ToNot(HaveOccurred())
+	registerSingleton(g, a, "changing", &changingRunner{
+		a: a,
+	})
+
+	// The generator is retrived when the application is started, so it's not rebuilt
+	// with the property changed before shutdown
+	g.Expect(Main(a)).To(Equal(3))
+}
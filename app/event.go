@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextRefreshed is published when the application is running and the bean definitions
+// are evaluated, it's the first event of application.
+type ContextRefreshed struct{}
+
+// ApplicationStarted is published after the Lifecycle components are started, and before
+// the runners are started.
+type ApplicationStarted struct{}
+
+// ApplicationReady is published after the runners are started.
+type ApplicationReady struct{}
+
+// ShutdownStarted is published when the application begin to shutdown.
+type ShutdownStarted struct {
+	// Reason is why the application is shutdown, eg: the received signal
+	Reason string
+}
+
+// ApplicationFailed is published when the Lifecycle component failed to start or the runner
+// return error, the application will been shutdown.
+type ApplicationFailed struct {
+	Err error
+}
+
+// EventPublisher publish the events to the EventListener beans.
+type EventPublisher interface {
+	// Publish the event, it's the built-in events or any user-defined value.
+	Publish(event interface{})
+}
+
+// EventPublisherAware is to be implemented by beans that want to publish events, the
+// EventPublisher is injected before the ContextRefreshed event.
+type EventPublisherAware interface {
+	SetEventPublisher(p EventPublisher)
+}
+
+// EventListener is to be implemented by beans that want to receive the events, the listener
+// receive all the events, and should ignore the events it's not interested with type switch, eg:
+//
+//	func (l *listener) OnEvent(event interface{}) {
+//		switch e := event.(type) {
+//		case app.ShutdownStarted:
+//			l.drain(e.Reason)
+//		}
+//	}
+type EventListener interface {
+	OnEvent(event interface{})
+}
+
+// AsyncEventListener is the EventListener which receive the events asynchronously, the
+// OnEvent will been called in a new goroutine if Async return true.
+type AsyncEventListener interface {
+	EventListener
+
+	// Async return true if the events should been delivered asynchronously
+	Async() bool
+}
+
+// eventMulticaster deliver the events to the listeners
+type eventMulticaster struct {
+	listeners []EventListener
+	lock      sync.RWMutex
+
+	// async is the asynchronous deliveries in flight, the pending is the count of them
+	async   sync.WaitGroup
+	pending int32
+}
+
+func (m *eventMulticaster) add(ls ...EventListener) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.listeners = append(m.listeners, ls...)
+}
+
+func (m *eventMulticaster) Publish(event interface{}) {
+	m.lock.RLock()
+	ls := append([]EventListener{}, m.listeners...)
+	m.lock.RUnlock()
+
+	for _, l := range ls {
+		if async, ok := l.(AsyncEventListener); ok && async.Async() {
+			m.async.Add(1)
+			atomic.AddInt32(&m.pending, 1)
+			go func(l EventListener) {
+				defer m.async.Done()
+				defer atomic.AddInt32(&m.pending, -1)
+				l.OnEvent(event)
+			}(l)
+			continue
+		}
+
+		l.OnEvent(event)
+	}
+}
+
+// wait block until all the asynchronous deliveries are finished, it return false if
+// they're not finished before ctx is done.
+func (m *eventMulticaster) wait(ctx context.Context) bool {
+	if atomic.LoadInt32(&m.pending) == 0 {
+		return true
+	}
+	return waitContext(ctx, m.async.Wait)
+}
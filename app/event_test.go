@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type eventRecorder struct {
+	recorder  *recorder
+	publisher EventPublisher
+}
+
+func (l *eventRecorder) OnEvent(event interface{}) {
+	l.recorder.record(fmt.Sprintf("%T", event))
+	if _, ok := event.(ApplicationReady); ok {
+		l.publisher.Publish("custom")
+	}
+}
+
+func (l *eventRecorder) SetEventPublisher(p EventPublisher) {
+	l.publisher = p
+}
+
+type asyncEventRecorder struct {
+	events chan interface{}
+}
+
+func (l *asyncEventRecorder) OnEvent(event interface{}) {
+	l.events <- event
+}
+
+func (l *asyncEventRecorder) Async() bool {
+	return true
+}
+
+func TestEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &recorder{}
+	a := NewApplication()
+	registerSingleton(g, a, "listener", &eventRecorder{recorder: r})
+	async := registerSingleton(g, a, "async", &asyncEventRecorder{events: make(chan interface{}, 10)}).(*asyncEventRecorder)
+	failing := &failingRunner{
+		wait: make(chan struct{}),
+		err:  errors.New("boom"),
+	}
+	registerSingleton(g, a, "failing", failing)
+
+	// The async events are delivered in separate goroutines, so the order is not guaranteed
+	receive := func(n int) []interface{} {
+		events := []interface{}{}
+		for i := 0; i < n; i++ {
+			var event interface{}
+			g.Eventually(async.events, time.Second).Should(Receive(&event))
+			events = append(events, event)
+		}
+		return events
+	}
+
+	ch := runAsync(a)
+	g.Expect(receive(4)).To(ConsistOf(ContextRefreshed{}, ApplicationStarted{}, ApplicationReady{}, "custom"))
+	close(failing.wait)
+
+	var err error
+	g.Eventually(ch, time.Second).Should(Receive(&err))
+	g.Expect(err).To(MatchError("boom"))
+	g.Expect(r.events).To(Equal([]string{
+		"app.ContextRefreshed",
+		"app.ApplicationStarted",
+		"app.ApplicationReady",
+		"string",
+		"app.ApplicationFailed",
+		"app.ShutdownStarted",
+	}))
+	g.Expect(receive(2)).To(ConsistOf(ApplicationFailed{Err: err}, ShutdownStarted{Reason: "Runner failed: boom"}))
+}
+
+func TestAddEventListener(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &recorder{}
+	a := NewApplication()
+	a.AddEventListener(&eventRecorder{recorder: r, publisher: a})
+	a.Publish(context.Canceled)
+	g.Expect(r.events).To(Equal([]string{"*errors.errorString"}))
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Status is the health status
+type Status string
+
+const (
+	// StatusUp defines the component or application is healthy
+	StatusUp Status = "UP"
+	// StatusDown defines the component or application is unhealthy
+	StatusDown Status = "DOWN"
+)
+
+// HealthIndicator is to be implemented by beans which report the health of component, eg:
+// the DB pool. The indicators are discovered when the application is running, and they
+// affect the readiness of application.
+type HealthIndicator interface {
+	// Health return the status of component, the ctx is done when the check should give up.
+	Health(ctx context.Context) Status
+}
+
+// LivenessIndicator is to be implemented by the HealthIndicator which also affects the
+// liveness of application, eg: the deadlock detector.
+type LivenessIndicator interface {
+	HealthIndicator
+
+	// AffectsLiveness return true if the application is not live when the component is down
+	AffectsLiveness() bool
+}
+
+// HealthReport is the health of application aggregated from the HealthIndicator beans
+type HealthReport struct {
+	// Liveness is down if the application is failed or any LivenessIndicator is down
+	Liveness Status `json:"liveness"`
+	// Readiness is up only if the application is ready and not shutdown, and all the
+	// HealthIndicator are up
+	Readiness Status `json:"readiness"`
+	// Components is the status of HealthIndicator by bean name
+	Components map[string]Status `json:"components"`
+}
+
+var healthIndicatorType = reflect.TypeOf((*HealthIndicator)(nil)).Elem()
+
+// healthState track the state of application by events, and hold the indicators
+type healthState struct {
+	ready  bool
+	failed bool
+
+	indicators map[string]HealthIndicator
+	lock       sync.RWMutex
+}
+
+func (s *healthState) OnEvent(event interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch event.(type) {
+	case ApplicationReady:
+		s.ready = true
+	case ShutdownStarted:
+		s.ready = false
+	case ApplicationFailed:
+		s.ready = false
+		s.failed = true
+	}
+}
+
+func (s *healthState) setIndicators(indicators map[string]HealthIndicator) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.indicators = indicators
+}
+
+// report check the indicators in parallel, and aggregate the statuses with the state
+func (s *healthState) report(ctx context.Context) HealthReport {
+	s.lock.RLock()
+	ready, failed := s.ready, s.failed
+	indicators := s.indicators
+	s.lock.RUnlock()
+
+	report := HealthReport{
+		Liveness:   StatusUp,
+		Readiness:  StatusUp,
+		Components: make(map[string]Status, len(indicators)),
+	}
+	if failed {
+		report.Liveness = StatusDown
+	}
+	if !ready {
+		report.Readiness = StatusDown
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for name, indicator := range indicators {
+		wg.Add(1)
+		go func(name string, indicator HealthIndicator) {
+			defer wg.Done()
+
+			status := indicator.Health(ctx)
+			lock.Lock()
+			defer lock.Unlock()
+			report.Components[name] = status
+			if status == StatusUp {
+				return
+			}
+
+			report.Readiness = StatusDown
+			if l, ok := indicator.(LivenessIndicator); ok && l.AffectsLiveness() {
+				report.Liveness = StatusDown
+			}
+		}(name, indicator)
+	}
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa"
+)
+
+type indicator struct {
+	status   Status
+	liveness bool
+}
+
+func (i *indicator) Health(ctx context.Context) Status {
+	return i.status
+}
+
+func (i *indicator) AffectsLiveness() bool {
+	return i.liveness
+}
+
+func TestHealthReport(t *testing.T) {
+	type testCase struct {
+		description string
+		events      []interface{}
+		indicators  map[string]HealthIndicator
+		expected    HealthReport
+	}
+	testCases := []testCase{
+		{
+			description: "not ready",
+			expected: HealthReport{
+				Liveness:   StatusUp,
+				Readiness:  StatusDown,
+				Components: map[string]Status{},
+			},
+		},
+		{
+			description: "ready",
+			events:      []interface{}{ApplicationReady{}},
+			indicators: map[string]HealthIndicator{
+				"db": &indicator{status: StatusUp},
+			},
+			expected: HealthReport{
+				Liveness:  StatusUp,
+				Readiness: StatusUp,
+				Components: map[string]Status{
+					"db": StatusUp,
+				},
+			},
+		},
+		{
+			description: "component down",
+			events:      []interface{}{ApplicationReady{}},
+			indicators: map[string]HealthIndicator{
+				"db":    &indicator{status: StatusDown},
+				"cache": &indicator{status: StatusUp, liveness: true},
+			},
+			expected: HealthReport{
+				Liveness:  StatusUp,
+				Readiness: StatusDown,
+				Components: map[string]Status{
+					"db":    StatusDown,
+					"cache": StatusUp,
+				},
+			},
+		},
+		{
+			description: "liveness component down",
+			events:      []interface{}{ApplicationReady{}},
+			indicators: map[string]HealthIndicator{
+				"deadlock": &indicator{status: StatusDown, liveness: true},
+			},
+			expected: HealthReport{
+				Liveness:  StatusDown,
+				Readiness: StatusDown,
+				Components: map[string]Status{
+					"deadlock": StatusDown,
+				},
+			},
+		},
+		{
+			description: "shutdown started",
+			events:      []interface{}{ApplicationReady{}, ShutdownStarted{Reason: "signal"}},
+			expected: HealthReport{
+				Liveness:   StatusUp,
+				Readiness:  StatusDown,
+				Components: map[string]Status{},
+			},
+		},
+		{
+			description: "application failed",
+			events:      []interface{}{ApplicationReady{}, ApplicationFailed{}},
+			expected: HealthReport{
+				Liveness:   StatusDown,
+				Readiness:  StatusDown,
+				Components: map[string]Status{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s := &healthState{}
+			for _, event := range tc.events {
+				s.OnEvent(event)
+			}
+			s.setIndicators(tc.indicators)
+			g.Expect(s.report(context.Background())).To(Equal(tc.expected))
+		})
+	}
+}
+
+// readinessRecorder record the readiness when the shutdown is started
+type readinessRecorder struct {
+	app       Application
+	readiness chan Status
+}
+
+func (r *readinessRecorder) OnEvent(event interface{}) {
+	if _, ok := event.(ShutdownStarted); ok {
+		r.readiness <- r.app.Health(context.Background()).Readiness
+	}
+}
+
+func TestApplicationHealth(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	blocking := &blockingRunner{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	registerSingleton(g, a, "blocking", blocking)
+	registerSingleton(g, a, "db", &indicator{status: StatusUp})
+	recorder := &readinessRecorder{
+		app:       a,
+		readiness: make(chan Status, 1),
+	}
+	registerSingleton(g, a, "recorder", recorder)
+	g.Expect(a.Health(context.Background()).Readiness).To(Equal(StatusDown))
+
+	ch := runAsync(a)
+	g.Eventually(func() HealthReport {
+		return a.Health(context.Background())
+	}, time.Second).Should(Equal(HealthReport{
+		Liveness:  StatusUp,
+		Readiness: StatusUp,
+		Components: map[string]Status{
+			"db": StatusUp,
+		},
+	}))
+
+	a.Shutdown()
+	g.Eventually(recorder.readiness, time.Second).Should(Receive(Equal(StatusDown)))
+	g.Eventually(ch, time.Second).Should(Receive(BeNil()))
+}
+
+// lifecycleIndicator is the Lifecycle which is healthy when it's running
+type lifecycleIndicator struct {
+	running int32
+}
+
+func (l *lifecycleIndicator) Start(ctx context.Context) error {
+	atomic.StoreInt32(&l.running, 1)
+	return nil
+}
+
+func (l *lifecycleIndicator) Stop(ctx context.Context) error {
+	atomic.StoreInt32(&l.running, 0)
+	return nil
+}
+
+func (l *lifecycleIndicator) IsRunning() bool {
+	return atomic.LoadInt32(&l.running) == 1
+}
+
+func (l *lifecycleIndicator) Health(ctx context.Context) Status {
+	if l.IsRunning() {
+		return StatusUp
+	}
+	return StatusDown
+}
+
+func TestApplicationHealthWithPrototype(t *testing.T) {
+	g := NewWithT(t)
+
+	a := NewApplication()
+	blocking := &blockingRunner{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	registerSingleton(g, a, "blocking", blocking)
+	// The prototype bean is the same instance for the Lifecycle and HealthIndicator
+	g.Expect(a.RegisterBeanDefinition("server", (&nuwa.BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*lifecycleIndicator)(nil)),
+	}).SetScope(nuwa.ScopePrototype))).ToNot(HaveOccurred())
+
+	ch := runAsync(a)
+	g.Eventually(func() HealthReport {
+		return a.Health(context.Background())
+	}, time.Second).Should(Equal(HealthReport{
+		Liveness:  StatusUp,
+		Readiness: StatusUp,
+		Components: map[string]Status{
+			"server": StatusUp,
+		},
+	}))
+
+	a.Shutdown()
+	g.Eventually(ch, time.Second).Should(Receive(BeNil()))
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// Lifecycle is to be implemented by the long-running components, eg: the DB pool, the
+// consumer or the HTTP server. The components are started before the runners, and stopped
+// after the runners are finished when the application is shutdown.
+type Lifecycle interface {
+	// Start the component, it should not block after the component is started.
+	Start(ctx context.Context) error
+
+	// Stop the component, the ctx is done when the shutdown timeout is exceeded.
+	Stop(ctx context.Context) error
+
+	// IsRunning return true if the component is running, only the running components are stopped.
+	IsRunning() bool
+}
+
+// Phased is to be implemented by the Lifecycle which need to been started in order, the
+// components are started in ascending order of phase and stopped in descending order, the
+// components in the same phase are started or stopped in parallel.
+// The phase of Lifecycle which doesn't implement Phased is 0.
+type Phased interface {
+	// Phase return the phase of component
+	Phase() int
+}
+
+// phaseOf return the phase of l
+func phaseOf(l Lifecycle) int {
+	if p, ok := l.(Phased); ok {
+		return p.Phase()
+	}
+	return 0
+}
+
+// groupByPhase return the components grouped by phase, the groups are in ascending order of phase
+func groupByPhase(ls []Lifecycle) [][]Lifecycle {
+	phases := map[int][]Lifecycle{}
+	for _, l := range ls {
+		phases[phaseOf(l)] = append(phases[phaseOf(l)], l)
+	}
+
+	keys := make([]int, 0, len(phases))
+	for phase := range phases {
+		keys = append(keys, phase)
+	}
+	sort.Ints(keys)
+
+	ret := make([][]Lifecycle, 0, len(keys))
+	for _, phase := range keys {
+		ret = append(ret, phases[phase])
+	}
+	return ret
+}
+
+// startLifecycles start the components in ascending order of phase, it will stop at the
+// first phase with failure, and the started components are left running.
+func startLifecycles(ctx context.Context, ls []Lifecycle) error {
+	for _, group := range groupByPhase(ls) {
+		err := inParallel(group, func(l Lifecycle) error {
+			if l.IsRunning() {
+				return nil
+			}
+
+			err := l.Start(ctx)
+			if err != nil {
+				return xerrors.Wrapf(err, "Cannot start '%T' in phase %v", l, phaseOf(l))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopLifecycles stop the running components in descending order of phase, all the phases
+// are stopped even if there is failure.
+func stopLifecycles(ctx context.Context, ls []Lifecycle) error {
+	groups := groupByPhase(ls)
+	errs := []error{}
+	for i := len(groups) - 1; i >= 0; i-- {
+		err := inParallel(groups[i], func(l Lifecycle) error {
+			if !l.IsRunning() {
+				return nil
+			}
+
+			err := l.Stop(ctx)
+			if err != nil {
+				return xerrors.Wrapf(err, "Cannot stop '%T' in phase %v", l, phaseOf(l))
+			}
+			return nil
+		})
+		errs = append(errs, err)
+	}
+	return joinErrors(errs)
+}
+
+// inParallel call fn with every component in parallel, and return the errors joined
+func inParallel(ls []Lifecycle, fn func(l Lifecycle) error) error {
+	errs := make([]error, len(ls))
+	var wg sync.WaitGroup
+	for i := range ls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(ls[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return joinErrors(errs)
+}
+
+// joinErrors return the errors joined, the nil errors are ignored
+func joinErrors(errs []error) error {
+	msgs := []string{}
+	var last error
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+			last = err
+		}
+	}
+
+	switch len(msgs) {
+	case 0:
+		return nil
+	case 1:
+		return last
+	}
+	return xerrors.Errorf("%v", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+type recorder struct {
+	events []string
+	lock   sync.Mutex
+}
+
+func (r *recorder) record(event string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.events = append(r.events, event)
+}
+
+type component struct {
+	name     string
+	phase    int
+	startErr error
+	running  bool
+	recorder *recorder
+}
+
+func (c *component) Start(ctx context.Context) error {
+	if c.startErr != nil {
+		return c.startErr
+	}
+	c.running = true
+	c.recorder.record("start " + c.name)
+	return nil
+}
+
+func (c *component) Stop(ctx context.Context) error {
+	c.running = false
+	c.recorder.record("stop " + c.name)
+	return nil
+}
+
+func (c *component) IsRunning() bool {
+	return c.running
+}
+
+func (c *component) Phase() int {
+	return c.phase
+}
+
+type unphasedComponent struct {
+	component
+}
+
+func (c *unphasedComponent) Phase() string {
+	return ""
+}
+
+func TestLifecycle(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &recorder{}
+	a := NewApplication()
+	registerSingleton(g, a, "http", &component{name: "http", phase: 2, recorder: r})
+	registerSingleton(g, a, "consumer", &component{name: "consumer", phase: 1, recorder: r})
+	registerSingleton(g, a, "db", &component{name: "db", phase: 0, recorder: r})
+	blocking := &blockingRunner{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+	registerSingleton(g, a, "blocking", blocking)
+
+	ch := runAsync(a)
+	g.Eventually(blocking.started, time.Second).Should(BeClosed())
+	g.Expect(r.events).To(Equal([]string{"start db", "start consumer", "start http"}))
+
+	a.Shutdown()
+	var err error
+	g.Eventually(ch, time.Second).Should(Receive(&err))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(r.events).To(Equal([]string{
+		"start db", "start consumer", "start http",
+		"stop http", "stop consumer", "stop db",
+	}))
+}
+
+func TestLifecycleWithStartError(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &recorder{}
+	a := NewApplication()
+	registerSingleton(g, a, "db", &component{name: "db", phase: -1, recorder: r})
+	registerSingleton(g, a, "consumer", &component{name: "consumer", phase: 1, recorder: r, startErr: errors.New("boom")})
+	registerSingleton(g, a, "http", &component{name: "http", phase: 2, recorder: r})
+
+	var err error
+	g.Eventually(runAsync(a), time.Second).Should(Receive(&err))
+	g.Expect(err).To(MatchError("Cannot start '*app.component' in phase 1: boom"))
+	g.Expect(r.events).To(Equal([]string{"start db", "stop db"}))
+}
+
+func TestGroupByPhase(t *testing.T) {
+	g := NewWithT(t)
+
+	c1 := &component{phase: 1}
+	c2 := &component{phase: -1}
+	c3 := &unphasedComponent{}
+	c4 := &component{phase: 1}
+	g.Expect(groupByPhase([]Lifecycle{c1, c2, c3, c4})).To(Equal([][]Lifecycle{
+		{c2},
+		{c3},
+		{c1, c4},
+	}))
+}
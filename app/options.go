@@ -0,0 +1,114 @@
+package app
+
+import (
+	"time"
+
+	"github.com/lsytj0413/nuwa"
+	"github.com/lsytj0413/nuwa/property"
+)
+
+// Option configure the Application created by New
+type Option func(o *options)
+
+// Bean is the bean definition registered by WithBeans
+type Bean struct {
+	Name       string
+	Definition nuwa.BeanDefinition
+}
+
+type options struct {
+	configFiles     []string
+	profiles        []string
+	envPrefix       string
+	env             bool
+	args            []string
+	beans           []Bean
+	shutdownTimeout time.Duration
+}
+
+// WithConfigFile load the config file with LoadConfigFile, the former file has the higher
+// precedence if the option is specified multiple times.
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFiles = append(o.configFiles, path)
+	}
+}
+
+// WithProfiles set the active profiles, it will hide the profiles specified by properties.
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = append(o.profiles, profiles...)
+	}
+}
+
+// WithEnvPrefix load the env vars with prefix by property.FromEnv, the env vars have
+// higher precedence than the config files.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+		o.env = true
+	}
+}
+
+// WithArgs parse the command-line args with ParseArgs, the args have the highest precedence.
+func WithArgs(args []string) Option {
+	return func(o *options) {
+		o.args = append([]string{}, args...)
+	}
+}
+
+// WithBeans register the bean definitions in order.
+func WithBeans(beans ...Bean) Option {
+	return func(o *options) {
+		o.beans = append(o.beans, beans...)
+	}
+}
+
+// WithShutdownTimeout set the shutdown timeout, see Application.SetShutdownTimeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// New return the Application configured by opts, the properties are assembled in order
+// of precedence: the args, the env vars and then the config files, so the active profiles
+// specified by args or env vars are applied to the config files.
+func New(opts ...Option) (Application, error) {
+	o := &options{
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	a := NewApplication().(*nuwaApplication)
+	a.SetShutdownTimeout(o.shutdownTimeout)
+	if len(o.profiles) != 0 {
+		a.SetActiveProfiles(o.profiles...)
+	}
+
+	if o.env {
+		a.properties.AddFirst(property.FromEnv(o.envPrefix))
+	}
+	if o.args != nil {
+		err := a.ParseArgs(o.args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range o.configFiles {
+		err := a.LoadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, bean := range o.beans {
+		err := a.RegisterBeanDefinition(bean.Name, bean.Definition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa"
+)
+
+func TestNew(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	g.Expect(ioutil.WriteFile(path, []byte("name: base\nport: 80\nhost: localhost\n"), 0644)).ToNot(HaveOccurred())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "app-prod.yaml"), []byte("name: prod\n"), 0644)).ToNot(HaveOccurred())
+
+	g.Expect(os.Setenv("NUWATEST_NUWA_PROFILES_ACTIVE", "prod")).ToNot(HaveOccurred())
+	g.Expect(os.Setenv("NUWATEST_PORT", "8080")).ToNot(HaveOccurred())
+	defer os.Unsetenv("NUWATEST_NUWA_PROFILES_ACTIVE")
+	defer os.Unsetenv("NUWATEST_PORT")
+
+	a, err := New(
+		WithConfigFile(path),
+		WithEnvPrefix("NUWATEST"),
+		WithArgs([]string{"--host=example.com"}),
+		WithBeans(Bean{
+			Name: "runner",
+			Definition: &nuwa.BeanDefinitionImpl{
+				Typ: reflect.TypeOf((*blockingRunner)(nil)),
+			},
+		}),
+		WithShutdownTimeout(time.Second),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(a.ActiveProfiles()).To(Equal([]string{"prod"}))
+	for key, expected := range map[string]string{
+		"name": "prod",
+		"port": "8080",
+		"host": "example.com",
+	} {
+		v, err := a.Get(key)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(v).To(Equal(expected), key)
+	}
+	g.Expect(a.GetBeanDefinitionNames()).To(Equal([]string{"runner"}))
+	g.Expect(a.(*nuwaApplication).shutdownTimeout).To(Equal(time.Second))
+}
+
+func TestNewWithProfiles(t *testing.T) {
+	g := NewWithT(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.json")
+	g.Expect(ioutil.WriteFile(path, []byte(`{"name": "base", "nuwa": {"profiles": {"active": "dev"}}}`), 0644)).ToNot(HaveOccurred())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "app-dev.json"), []byte(`{"name": "dev"}`), 0644)).ToNot(HaveOccurred())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "app-test.json"), []byte(`{"name": "test"}`), 0644)).ToNot(HaveOccurred())
+
+	a, err := New(WithConfigFile(path), WithProfiles("test"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(a.ActiveProfiles()).To(Equal([]string{"test"}))
+	v, err := a.Get("name")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("test"))
+
+	_, err = New(WithConfigFile(filepath.Join(dir, "missing.json")))
+	g.Expect(err).To(HaveOccurred())
+}
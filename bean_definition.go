@@ -2,6 +2,8 @@ package nuwa
 
 import (
 	"reflect"
+
+	"github.com/lsytj0413/nuwa/property"
 )
 
 // BeanDefinition is the definition of bean
@@ -29,6 +31,15 @@ type BeanDefinition interface {
 
 	// FieldDescriptors return the struct field descriptors
 	FieldDescriptors() []FieldDescriptor
+
+	// Profiles return the profiles which the bean belongs to, the bean is active if any of
+	// the profiles is active. The profile with prefix '!' is active when the profile is not active.
+	// The bean is always active if the profiles is empty.
+	Profiles() []string
+
+	// Conditions return the conditions of bean, the bean is active only if all of
+	// the conditions are matched.
+	Conditions() []Condition
 }
 
 // FieldDescriptor is the descriptor for struct field
@@ -50,6 +61,13 @@ type FieldDescriptor struct {
 // PropertyFieldDescriptor is the descriptor for property autowired value.
 type PropertyFieldDescriptor struct {
 	Name string
+
+	// Hint is the hint for converting the property value, eg: property.HintByteSize
+	Hint property.Hint
+
+	// Validate is the validation rules of property value, eg: required,min=1,max=65535.
+	// See property.ValidateValue for details.
+	Validate string
 }
 
 // BeanFieldDescriptor is the descriptor for bean autowired value.
@@ -71,6 +89,8 @@ type BeanDefinitionImpl struct {
 	initMethodName    string
 	destroyMethodName string
 	fieldDescriptors  []FieldDescriptor
+	profiles          []string
+	conditions        []Condition
 }
 
 func (b *BeanDefinitionImpl) Type() reflect.Type {
@@ -104,3 +124,35 @@ func (b *BeanDefinitionImpl) DestroyMethodName() string {
 func (b *BeanDefinitionImpl) FieldDescriptors() []FieldDescriptor {
 	return b.fieldDescriptors
 }
+
+func (b *BeanDefinitionImpl) Profiles() []string {
+	return b.profiles
+}
+
+// SetProfiles set the profiles of bean
+func (b *BeanDefinitionImpl) SetProfiles(profiles ...string) *BeanDefinitionImpl {
+	b.profiles = profiles
+	return b
+}
+
+func (b *BeanDefinitionImpl) Conditions() []Condition {
+	return b.conditions
+}
+
+// SetConditions set the conditions of bean
+func (b *BeanDefinitionImpl) SetConditions(conditions ...Condition) *BeanDefinitionImpl {
+	b.conditions = conditions
+	return b
+}
+
+// SetDestroyMethodName set the destroy method of bean, the method must be func() or func() error
+func (b *BeanDefinitionImpl) SetDestroyMethodName(name string) *BeanDefinitionImpl {
+	b.destroyMethodName = name
+	return b
+}
+
+// SetScope set the scope of bean
+func (b *BeanDefinitionImpl) SetScope(scope Scope) *BeanDefinitionImpl {
+	b.scope = scope
+	return b
+}
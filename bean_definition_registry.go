@@ -20,6 +20,9 @@ type BeanDefinitionRegistry interface {
 	GetBeanDefinition(beanName string) (BeanDefinition, error)
 
 	GetAllBeanDefinition() map[string]BeanDefinition
+
+	// GetBeanDefinitionNames return the names of all bean definitions in registration order.
+	GetBeanDefinitionNames() []string
 }
 
 // NewBeanDefinitionRegistry return the BeanDefinitionRegistry impl
@@ -30,8 +33,9 @@ func NewBeanDefinitionRegistry() BeanDefinitionRegistry {
 }
 
 type beanDefinitionRegistryImpl struct {
-	beanDefinitionMap map[string]BeanDefinition
-	lock              sync.RWMutex
+	beanDefinitionMap   map[string]BeanDefinition
+	beanDefinitionNames []string
+	lock                sync.RWMutex
 }
 
 func (r *beanDefinitionRegistryImpl) RegisterBeanDefinition(
@@ -47,6 +51,7 @@ func (r *beanDefinitionRegistryImpl) RegisterBeanDefinition(
 	}
 
 	r.beanDefinitionMap[beanName] = beanDefinition
+	r.beanDefinitionNames = append(r.beanDefinitionNames, beanName)
 	return nil
 }
 
@@ -60,6 +65,12 @@ func (r *beanDefinitionRegistryImpl) RemoveBeanDefinition(beanName string) error
 	}
 
 	delete(r.beanDefinitionMap, beanName)
+	for i, name := range r.beanDefinitionNames {
+		if name == beanName {
+			r.beanDefinitionNames = append(r.beanDefinitionNames[:i], r.beanDefinitionNames[i+1:]...)
+			break
+		}
+	}
 	return nil
 }
 
@@ -86,3 +97,10 @@ func (r *beanDefinitionRegistryImpl) GetAllBeanDefinition() map[string]BeanDefin
 
 	return ret
 }
+
+func (r *beanDefinitionRegistryImpl) GetBeanDefinitionNames() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append([]string{}, r.beanDefinitionNames...)
+}
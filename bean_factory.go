@@ -1,8 +1,15 @@
 package nuwa
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/lsytj0413/nuwa/logger"
 	"github.com/lsytj0413/nuwa/property"
 	"github.com/lsytj0413/nuwa/utils"
 	"github.com/lsytj0413/nuwa/xerrors"
@@ -11,36 +18,641 @@ import (
 // BeanFactory providing the full capabilities of SPI.
 type BeanFactory interface {
 	// GetBean return an instance, which may be shared or independent, of the specified bean.
+	// The bean with ScopeSingleton or ScopeRefresh is shared, others are independent.
 	GetBean(name string) (interface{}, error)
+
+	// GetBeanProvider return the provider of the specified bean, the caller should
+	// hold the provider instead of the instance if the bean may been refreshed.
+	GetBeanProvider(name string) BeanProvider
+
 	RetriveBean(name string, bean interface{}) error
 	RetriveBeans(beans interface{}) error
 
+	// ContainsBean return true if the active bean with name is defined by this factory
+	// or its ancestors.
+	ContainsBean(name string) bool
+
+	// GetBeanNamesForType return the names of active beans which can been assigned to typ,
+	// the beans of this factory are followed by the beans of ancestors which are not hidden.
+	GetBeanNamesForType(typ reflect.Type) []string
+
+	// ParentBeanFactory return the parent factory, or nil if there is no parent.
+	ParentBeanFactory() BeanFactory
+
+	// Close detach the child factory from parent, so the child is not retained by parent
+	// any more. It doesn't destroy the singletons, the DestroySingletons should been called
+	// before. It's no-op for the factory without parent.
+	Close()
+
+	// SetActiveProfiles set the active profiles explicitly, it will hide the profiles
+	// specified by the property ProfilesActivePropertyName.
+	SetActiveProfiles(profiles ...string)
+
+	// ActiveProfiles return the active profiles, only the bean definitions matching the
+	// active profiles can be instantiated.
+	ActiveProfiles() []string
+
+	// ConditionReport return the evaluation report of profiles and conditions for all
+	// bean definitions, which explain why the bean is active or not.
+	ConditionReport() []ConditionEvaluation
+
+	// DestroySingletons destroy the shared bean instances in the reverse order of creation,
+	// so the bean is destroyed before the beans it depends on. The bean is destroyed by
+	// DisposableBean.Destroy and the method named by BeanDefinition.DestroyMethodName.
+	// The beans which failed or are not destroyed before ctx is done are reported by the error.
+	DestroySingletons(ctx context.Context) error
+
 	AliasRegistry
 	BeanDefinitionRegistry
 	property.Properties
+	property.RawGetter
 }
 
 // NewBeanFactory return the BeanFactory impl
 func NewBeanFactory() BeanFactory {
-	return &beanFactoryImpl{
+	return NewBeanFactoryWithProperties(property.NewCompositeProperties(property.NewProperties()))
+}
+
+// NewChildBeanFactory return the BeanFactory impl which resolve the beans and properties it
+// doesn't define from parent. The bean definitions and properties of child hide the ones of
+// parent with the same name, and the child is destroyed independently, the singletons of
+// parent are never destroyed by child. The child should been closed by Close after it's
+// destroyed, because it observe the property changes of parent.
+// The child read the raw values of parent, and decrypt them with the Decryptor of parent,
+// so the values are decrypted and the placeholders are resolved only once.
+func NewChildBeanFactory(parent BeanFactory) BeanFactory {
+	p := property.NewCompositeProperties(property.NewProperties(), parent)
+	p.SetDecryptor(&parentDecryptor{
+		parent: parent,
+	})
+	f := NewBeanFactoryWithProperties(p).(*beanFactoryImpl)
+	f.parent = parent
+	f.closeProperties = p.Close
+	return f
+}
+
+// NewBeanFactoryWithProperties return the BeanFactory impl which use p to resolve property values.
+// If p is property.Observable, the bean with ScopeRefresh will been rebuilt when the properties
+// it depends on are changed.
+func NewBeanFactoryWithProperties(p property.Properties) BeanFactory {
+	f := &beanFactoryImpl{
 		AliasRegistry:          NewAliasRegistry(),
 		BeanDefinitionRegistry: NewBeanDefinitionRegistry(),
-		Properties:             property.NewProperties(),
+		Properties:             p,
+		instances:              make(map[string]interface{}),
+	}
+	if o, ok := p.(property.Observable); ok {
+		o.AddChangeListener(f.refresh)
 	}
+	return f
+}
+
+// decryptorHolder is implemented by the BeanFactory which hold the Decryptor of properties
+type decryptorHolder interface {
+	Decryptor() property.Decryptor
+}
+
+// parentDecryptor decrypt with the Decryptor of parent, which may been set after the child
+// is created
+type parentDecryptor struct {
+	parent BeanFactory
+}
+
+func (d *parentDecryptor) Decrypt(ciphertext string) (string, error) {
+	if h, ok := d.parent.(decryptorHolder); ok {
+		if decryptor := h.Decryptor(); decryptor != nil {
+			return decryptor.Decrypt(ciphertext)
+		}
+	}
+	return "", xerrors.Errorf("Cannot decrypt with parent factory, no decryptor")
+}
+
+// evaluation is the result of the conditions evaluation
+type evaluation struct {
+	ctx    *conditionContext
+	report []ConditionEvaluation
 }
 
 type beanFactoryImpl struct {
 	AliasRegistry
 	BeanDefinitionRegistry
 	property.Properties
+
+	parent BeanFactory
+	// closeProperties remove the listeners registered with parent
+	closeProperties func()
+	activeProfiles  []string
+	lock            sync.RWMutex
+
+	// evaluated is the cached result of evaluate, it's invalidated when the bean
+	// definitions or the active profiles are changed
+	evaluated      *evaluation
+	evaluationLock sync.Mutex
+
+	// instances hold the shared bean instances, the instanceNames is the names
+	// of instances in the order of creation
+	instances     map[string]interface{}
+	instanceNames []string
+	instancesLock sync.RWMutex
+	// destroying is the destroys of refreshed instances in background, the pending is
+	// the count of them
+	destroying sync.WaitGroup
+	pending    int32
 }
 
-func (f *beanFactoryImpl) GetBean(name string) (interface{}, error) {
+func (f *beanFactoryImpl) GetRaw(key string) (string, error) {
+	return property.GetRaw(f.Properties, key)
+}
+
+// Decryptor return the Decryptor of properties, or nil if it's not set
+func (f *beanFactoryImpl) Decryptor() property.Decryptor {
+	if h, ok := f.Properties.(decryptorHolder); ok {
+		return h.Decryptor()
+	}
+	return nil
+}
+
+func (f *beanFactoryImpl) SetActiveProfiles(profiles ...string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.activeProfiles = append([]string{}, profiles...)
+	f.invalidateEvaluation()
+	if v, err := f.Get(ProfilesActivePropertyName); err == nil {
+		logger.Default().Info("Active profiles overridden", "profiles", f.activeProfiles, "property", v)
+	}
+}
+
+func (f *beanFactoryImpl) ActiveProfiles() []string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if len(f.activeProfiles) != 0 {
+		return append([]string{}, f.activeProfiles...)
+	}
+
+	profiles, err := f.Get(ProfilesActivePropertyName)
+	if err != nil {
+		return []string{}
+	}
+	return ParseProfiles(profiles)
+}
+
+// invalidateEvaluation remove the cached result of evaluate, so the conditions will been
+// evaluated again on next lookup
+func (f *beanFactoryImpl) invalidateEvaluation() {
+	f.evaluationLock.Lock()
+	defer f.evaluationLock.Unlock()
+
+	f.evaluated = nil
+}
+
+// evaluate return the context which contains the active bean definitions, and the
+// evaluation report of all bean definitions. The result is cached until the bean
+// definitions or the active profiles are changed, so the conditions are evaluated once
+// and the active beans are consistent with the instantiated ones, eg: after the
+// properties are reloaded.
+func (f *beanFactoryImpl) evaluate() (*conditionContext, []ConditionEvaluation) {
+	f.evaluationLock.Lock()
+	defer f.evaluationLock.Unlock()
+
+	if f.evaluated == nil {
+		ctx, report := f.evaluateConditions()
+		f.evaluated = &evaluation{
+			ctx:    ctx,
+			report: report,
+		}
+	}
+	return f.evaluated.ctx, f.evaluated.report
+}
+
+// evaluateConditions evaluate the conditions of all bean definitions. The bean definitions
+// without conditions are evaluated first, then the others are evaluated in registration
+// order, so the condition can only see the beans which are evaluated before.
+func (f *beanFactoryImpl) evaluateConditions() (*conditionContext, []ConditionEvaluation) {
+	ctx := &conditionContext{
+		Properties:  f.Properties,
+		parent:      f.parent,
+		activeBeans: make(map[string]BeanDefinition),
+	}
+	report := []ConditionEvaluation{}
+
+	activeProfiles := f.ActiveProfiles()
+	beanDefinitions := f.GetAllBeanDefinition()
+	beanNames := f.GetBeanDefinitionNames()
+	conditionalBeanNames := []string{}
+	evaluateBean := func(name string) {
+		beanDefinition, ok := beanDefinitions[name]
+		if !ok {
+			return
+		}
+
+		evaluation := ConditionEvaluation{
+			BeanName: name,
+			Match:    true,
+		}
+		if !AcceptsProfiles(activeProfiles, beanDefinition.Profiles()) {
+			evaluation.Match = false
+			evaluation.Outcomes = append(evaluation.Outcomes, ConditionOutcome{
+				Match:   false,
+				Message: fmt.Sprintf("Profiles: %v not accepted by active profiles %v", beanDefinition.Profiles(), activeProfiles),
+			})
+		} else {
+			for _, condition := range beanDefinition.Conditions() {
+				outcome := condition(ctx)
+				evaluation.Outcomes = append(evaluation.Outcomes, outcome)
+				if !outcome.Match {
+					evaluation.Match = false
+					break
+				}
+			}
+		}
+
+		if evaluation.Match {
+			ctx.activate(name, beanDefinition)
+		}
+		report = append(report, evaluation)
+	}
+
+	for _, name := range beanNames {
+		beanDefinition, ok := beanDefinitions[name]
+		if ok && len(beanDefinition.Conditions()) != 0 {
+			conditionalBeanNames = append(conditionalBeanNames, name)
+			continue
+		}
+		evaluateBean(name)
+	}
+	for _, name := range conditionalBeanNames {
+		evaluateBean(name)
+	}
+
+	return ctx, report
+}
+
+func (f *beanFactoryImpl) ConditionReport() []ConditionEvaluation {
+	_, report := f.evaluate()
+	return append([]ConditionEvaluation{}, report...)
+}
+
+// getActiveBeanDefinition return the bean definition for the given bean name, it will
+// return err if the bean definition is not active.
+func (f *beanFactoryImpl) getActiveBeanDefinition(name string) (BeanDefinition, error) {
 	beanDefinition, err := f.GetBeanDefinition(name)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, report := f.evaluate()
+	if ctx.isActive(name) {
+		return beanDefinition, nil
+	}
+
+	for _, evaluation := range report {
+		if evaluation.BeanName == name && len(evaluation.Outcomes) != 0 {
+			return nil, xerrors.Errorf("Bean '%v' is not active: %v", name, evaluation.Outcomes[len(evaluation.Outcomes)-1].Message)
+		}
+	}
+	return nil, xerrors.Errorf("Bean '%v' is not active", name)
+}
+
+// isLocalBean return true if the bean of name is resolved by f instead of parent, which
+// is true if there is no parent, or the bean definition is registered with f and either
+// it's active or the parent doesn't contain the bean. So the inactive bean definition
+// doesn't hide the bean of parent, eg: the child may override the bean for some profiles.
+func (f *beanFactoryImpl) isLocalBean(name string) bool {
+	if f.parent == nil {
+		return true
+	}
+	_, err := f.GetBeanDefinition(name)
+	if err != nil {
+		return false
+	}
+
+	ctx, _ := f.evaluate()
+	return ctx.isActive(name) || !f.parent.ContainsBean(name)
+}
+
+func (f *beanFactoryImpl) ParentBeanFactory() BeanFactory {
+	return f.parent
+}
+
+func (f *beanFactoryImpl) ContainsBean(name string) bool {
+	if !f.isLocalBean(name) {
+		return f.parent.ContainsBean(name)
+	}
+
+	ctx, _ := f.evaluate()
+	return ctx.isActive(name)
+}
+
+func (f *beanFactoryImpl) GetBeanNamesForType(typ reflect.Type) []string {
+	ctx, _ := f.evaluate()
+	names := []string{}
+	for _, name := range ctx.activeBeanNames {
+		if isAssignableBeanType(ctx.activeBeans[name].Type(), typ) {
+			names = append(names, name)
+		}
+	}
+	if f.parent == nil {
+		return names
+	}
+
+	for _, name := range f.parent.GetBeanNamesForType(typ) {
+		if !f.isLocalBean(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RegisterBeanDefinition register the bean definition, which may hide the bean of parent
+func (f *beanFactoryImpl) RegisterBeanDefinition(name string, beanDefinition BeanDefinition) error {
+	err := f.BeanDefinitionRegistry.RegisterBeanDefinition(name, beanDefinition)
+	if err != nil {
+		return err
+	}
+	f.invalidateEvaluation()
+
+	if f.parent != nil && f.parent.ContainsBean(name) {
+		logger.Default().Info("Bean definition overrides parent", "name", name, "type", beanDefinition.Type())
+	}
+	return nil
+}
+
+func (f *beanFactoryImpl) GetBean(name string) (interface{}, error) {
+	if !f.isLocalBean(name) {
+		return f.parent.GetBean(name)
+	}
+
+	beanDefinition, err := f.getActiveBeanDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSharedScope(beanDefinition.Scope()) {
+		return f.createBean(name, beanDefinition)
+	}
+
+	f.instancesLock.RLock()
+	obj, ok := f.instances[name]
+	f.instancesLock.RUnlock()
+	if ok {
+		return obj, nil
+	}
+
+	// NOTE: the bean is created without lock, because it may depends on other shared beans.
+	// If there is a race, the first stored instance wins.
+	obj, err = f.createBean(name, beanDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	f.instancesLock.Lock()
+	defer f.instancesLock.Unlock()
+	if exists, ok := f.instances[name]; ok {
+		return exists, nil
+	}
+	f.instances[name] = obj
+	f.instanceNames = append(f.instanceNames, name)
+	return obj, nil
+}
+
+// waitDestroying wait the destroys of refreshed instances, it return false if ctx is done before
+func (f *beanFactoryImpl) waitDestroying(ctx context.Context) bool {
+	if atomic.LoadInt32(&f.pending) == 0 {
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		f.destroying.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// removeInstance remove the shared instance of name
+// NOTE: the f.instancesLock must been held
+func (f *beanFactoryImpl) removeInstance(name string) {
+	delete(f.instances, name)
+	for i, n := range f.instanceNames {
+		if n == name {
+			f.instanceNames = append(f.instanceNames[:i], f.instanceNames[i+1:]...)
+			break
+		}
+	}
+}
+
+func (f *beanFactoryImpl) DestroySingletons(ctx context.Context) error {
+	msgs := []string{}
+	if !f.waitDestroying(ctx) {
+		msgs = append(msgs, fmt.Sprintf("refreshed beans are not destroyed: %v", ctx.Err()))
+	}
+
+	f.instancesLock.Lock()
+	names := f.instanceNames
+	instances := f.instances
+	f.instances = make(map[string]interface{})
+	f.instanceNames = nil
+	f.instancesLock.Unlock()
+
+	for i := len(names) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			msgs = append(msgs, fmt.Sprintf("beans %v are not destroyed: %v", names[:i+1], ctx.Err()))
+			break
+		}
+
+		finished, err := f.destroyBeanWithin(ctx, names[i], instances[names[i]])
+		if !finished {
+			msgs = append(msgs, fmt.Sprintf("beans %v are not destroyed: %v", names[:i+1], ctx.Err()))
+			break
+		}
+		if err != nil {
+			logger.Default().Warn("Cannot destroy bean", "name", names[i], "err", err)
+			msgs = append(msgs, err.Error())
+			continue
+		}
+		logger.Default().Debug("Bean destroyed", "name", names[i])
+	}
+
+	if len(msgs) != 0 {
+		return xerrors.Errorf("Cannot destroy singletons: %v", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// destroyBeanWithin destroy the bean in goroutine, and return false if it's not finished
+// before ctx is done, the blocked destroy is left running.
+func (f *beanFactoryImpl) destroyBeanWithin(ctx context.Context, name string, obj interface{}) (bool, error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- f.destroyBean(name, obj)
+	}()
+
+	select {
+	case err := <-done:
+		return true, err
+	case <-ctx.Done():
+		return false, nil
+	}
+}
+
+func (f *beanFactoryImpl) destroyBean(name string, obj interface{}) error {
+	if d, ok := obj.(DisposableBean); ok {
+		err := d.Destroy()
+		if err != nil {
+			return xerrors.Wrapf(err, "Cannot destroy bean '%v'", name)
+		}
+	}
+
+	beanDefinition, err := f.GetBeanDefinition(name)
+	if err != nil || beanDefinition.DestroyMethodName() == "" {
+		return nil
+	}
+
+	method := reflect.ValueOf(obj).MethodByName(beanDefinition.DestroyMethodName())
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() > 1 ||
+		(method.Type().NumOut() == 1 && method.Type().Out(0) != errorType) {
+		return xerrors.Errorf("Cannot destroy bean '%v', the destroy method '%v' must be func() or func() error", name, beanDefinition.DestroyMethodName())
+	}
+
+	out := method.Call(nil)
+	if len(out) == 1 && !out[0].IsNil() {
+		return xerrors.Wrapf(out[0].Interface().(error), "Cannot destroy bean '%v'", name)
+	}
+	return nil
+}
+
+func (f *beanFactoryImpl) RemoveBeanDefinition(beanName string) error {
+	err := f.BeanDefinitionRegistry.RemoveBeanDefinition(beanName)
+	if err != nil {
+		return err
+	}
+	f.invalidateEvaluation()
+
+	f.instancesLock.Lock()
+	defer f.instancesLock.Unlock()
+	f.removeInstance(beanName)
+	return nil
+}
+
+func (f *beanFactoryImpl) GetBeanProvider(name string) BeanProvider {
+	return &beanProviderImpl{
+		name:    name,
+		factory: f,
+	}
+}
+
+// AddChangeListener register the listener for the changes of properties if they're
+// property.Observable, so the child factory can observe the changes of parent.
+func (f *beanFactoryImpl) AddChangeListener(l property.ChangeListener) func() {
+	if o, ok := f.Properties.(property.Observable); ok {
+		return o.AddChangeListener(l)
+	}
+	return func() {}
+}
+
+func (f *beanFactoryImpl) Close() {
+	if f.closeProperties != nil {
+		f.closeProperties()
+	}
+}
+
+// refresh remove the instances of refresh scope which depends on the changed properties,
+// so they will been rebuilt on next GetBean, and destroy the removed instances which are
+// not referenced by other shared instances. The referenced instances are left to the
+// referrers, otherwise they will use the destroyed instances.
+func (f *beanFactoryImpl) refresh(events []property.ChangeEvent) {
+	evicted := f.evictRefreshedInstances(events)
+	if len(evicted) == 0 {
+		return
+	}
+
+	// NOTE: the destroy may be slow, so it's called in background to not block the
+	// publisher of events, eg: Reload or Set
+	f.destroying.Add(1)
+	atomic.AddInt32(&f.pending, 1)
+	go func() {
+		defer f.destroying.Done()
+		defer atomic.AddInt32(&f.pending, -1)
+		for name, obj := range evicted {
+			err := f.destroyBean(name, obj)
+			if err != nil {
+				logger.Default().Warn("Cannot destroy refreshed bean", "name", name, "err", err)
+			}
+		}
+	}()
+}
+
+// evictRefreshedInstances remove the refresh scope instances which depend on the changed
+// properties, and return the removed instances by name which should been destroyed
+func (f *beanFactoryImpl) evictRefreshedInstances(events []property.ChangeEvent) map[string]interface{} {
+	f.instancesLock.Lock()
+	defer f.instancesLock.Unlock()
+
+	evicted := map[string]interface{}{}
+	for name, obj := range f.instances {
+		beanDefinition, err := f.GetBeanDefinition(name)
+		if err != nil || beanDefinition.Scope() != ScopeRefresh {
+			continue
+		}
+
+		if key, ok := changedPropertyOf(beanDefinition, events); ok {
+			logger.Default().Info("Bean refreshed", "name", name, "key", key)
+			f.removeInstance(name)
+			evicted[name] = obj
+		}
+	}
+
+	for name := range f.instances {
+		beanDefinition, err := f.GetBeanDefinition(name)
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range beanDefinition.FieldDescriptors() {
+			if fd.Bean == nil {
+				continue
+			}
+			dependency := f.CanonicalName(fd.Bean.Name)
+			if _, ok := evicted[dependency]; ok {
+				logger.Default().Info("Refreshed bean is not destroyed, it's referenced", "name", dependency, "referrer", name)
+				delete(evicted, dependency)
+			}
+		}
+	}
+	return evicted
+}
+
+// changedPropertyOf return the changed property which the bean definition depend on, the
+// property is changed if the event key is the property or the sub key of it
+func changedPropertyOf(beanDefinition BeanDefinition, events []property.ChangeEvent) (string, bool) {
+	for _, fd := range beanDefinition.FieldDescriptors() {
+		if fd.Property == nil {
+			continue
+		}
+
+		for _, event := range events {
+			if property.IsSubKey(event.Key, fd.Property.Name) {
+				return fd.Property.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// createBean create the instance of bean, the elapsed time includes the creation of the
+// beans it depends on.
+func (f *beanFactoryImpl) createBean(name string, beanDefinition BeanDefinition) (interface{}, error) {
+	start := time.Now()
 	v, err := NewValue(beanDefinition.Type())
 	if err != nil {
 		return nil, err
@@ -50,16 +662,21 @@ func (f *beanFactoryImpl) GetBean(name string) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	logger.Default().Debug("Bean created", "name", name, "type", beanDefinition.Type(), "scope", beanDefinition.Scope(), "elapsed", time.Since(start))
 	return v.Interface(), nil
 }
 
 func (f *beanFactoryImpl) RetriveBean(name string, bean interface{}) error {
+	if !f.isLocalBean(name) {
+		return f.parent.RetriveBean(name, bean)
+	}
+
 	v, err := utils.IndirectToSetableValue(bean)
 	if err != nil {
 		return err
 	}
 
-	beanDefinition, err := f.GetBeanDefinition(name)
+	beanDefinition, err := f.getActiveBeanDefinition(name)
 	if err != nil {
 		return err
 	}
@@ -69,6 +686,8 @@ func (f *beanFactoryImpl) RetriveBean(name string, bean interface{}) error {
 		typ = typ.Elem()
 	}
 
+	// The violations of all property fields are returned at once
+	violations := property.ValidationErrors{}
 	switch typ.Kind() {
 	case reflect.Struct:
 		for _, fd := range beanDefinition.FieldDescriptors() {
@@ -83,11 +702,27 @@ func (f *beanFactoryImpl) RetriveBean(name string, bean interface{}) error {
 				// 	fv = fv.Elem()
 				// }
 
-				err = f.Retrive(fd.Property.Name, fv)
+				var target interface{} = fv
+				if fd.Property.Hint != "" {
+					target = property.WithHint(fv, fd.Property.Hint)
+				}
+				present := true
+				err = f.Retrive(fd.Property.Name, target)
 				if err != nil {
-					return err
+					var errs property.ValidationErrors
+					switch {
+					case xerrors.As(err, &errs):
+						violations = append(violations, errs...)
+					case fd.Property.Validate != "" && xerrors.Is(err, xerrors.ErrNotFound):
+						present = false
+					default:
+						return err
+					}
 				}
 
+				if fd.Property.Validate != "" {
+					violations = append(violations, property.ValidateValue(fd.Property.Name, fv.Interface(), present, fd.Property.Validate)...)
+				}
 				continue
 			}
 
@@ -103,6 +738,20 @@ func (f *beanFactoryImpl) RetriveBean(name string, bean interface{}) error {
 		}
 	}
 
+	if validator, ok := v.Addr().Interface().(property.Validator); ok {
+		err = validator.Validate()
+		if err != nil {
+			var errs property.ValidationErrors
+			if !xerrors.As(err, &errs) {
+				return xerrors.Wrapf(err, "Cannot validate bean '%v'", name)
+			}
+			violations = append(violations, errs...)
+		}
+	}
+
+	if len(violations) != 0 {
+		return xerrors.Wrapf(violations, "Cannot validate bean '%v'", name)
+	}
 	return nil
 }
 
@@ -116,24 +765,8 @@ func (f *beanFactoryImpl) RetriveBeans(bean interface{}) error {
 		return xerrors.Errorf("cannot retrive beans to '%T', is must be *slice", bean)
 	}
 
-	typ := v.Type().Elem()
-	beanDefinitions := f.GetAllBeanDefinition()
-	beanNames := []string{}
-
-	for k, v := range beanDefinitions {
-		if typ.Kind() == reflect.Interface {
-			if v.Type().Implements(typ) {
-				beanNames = append(beanNames, k)
-			}
-		} else {
-			if v.Type() == typ {
-				beanNames = append(beanNames, k)
-			}
-		}
-	}
-
 	ret := reflect.MakeSlice(v.Type(), 0, 0)
-	for _, name := range beanNames {
+	for _, name := range f.GetBeanNamesForType(v.Type().Elem()) {
 		b, err := f.GetBean(name)
 		if err != nil {
 			return err
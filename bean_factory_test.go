@@ -1,10 +1,15 @@
 package nuwa
 
 import (
+	"bytes"
+	"context"
 	"reflect"
 	"testing"
 
 	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/property"
+	"github.com/lsytj0413/nuwa/xerrors"
 )
 
 type BeanOnlyBeanField struct {
@@ -242,3 +247,567 @@ func TestGetBean(t *testing.T) {
 		})
 	}
 }
+
+func TestGetBeanWithScope(t *testing.T) {
+	g := NewWithT(t)
+
+	f := NewBeanFactory()
+	propertyField := []FieldDescriptor{
+		{
+			FieldIndex: 0,
+			Name:       "V",
+			Typ:        reflect.TypeOf(int(0)),
+			Property: &PropertyFieldDescriptor{
+				Name: "val",
+			},
+		},
+	}
+	err := f.RegisterBeanDefinition("singleton", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField,
+	}).SetScope(ScopeSingleton))
+	g.Expect(err).ToNot(HaveOccurred())
+	err = f.RegisterBeanDefinition("refresh", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField,
+	}).SetScope(ScopeRefresh))
+	g.Expect(err).ToNot(HaveOccurred())
+	err = f.RegisterBeanDefinition("prototype", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField,
+	}).SetScope(ScopePrototype))
+	g.Expect(err).ToNot(HaveOccurred())
+	err = f.Set("val", 100)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	singleton, err := f.GetBean("singleton")
+	g.Expect(err).ToNot(HaveOccurred())
+	refresh, err := f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	prototype, err := f.GetBean("prototype")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	obj, err := f.GetBean("singleton")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(singleton))
+	obj, err = f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(refresh))
+	obj, err = f.GetBean("prototype")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(prototype))
+
+	// The refresh bean will been rebuilt after the property changed
+	provider := f.GetBeanProvider("refresh")
+	err = f.Set("other", 1)
+	g.Expect(err).ToNot(HaveOccurred())
+	obj, err = provider.Get()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(refresh))
+
+	err = f.Set("val", 200)
+	g.Expect(err).ToNot(HaveOccurred())
+	obj, err = provider.Get()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(refresh))
+	g.Expect(obj.(*BeanOnlyPropertyField).V).To(Equal(200))
+
+	obj, err = f.GetBean("singleton")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(singleton))
+	g.Expect(obj.(*BeanOnlyPropertyField).V).To(Equal(100))
+}
+
+type BeanValidated struct {
+	Port int
+	Name string
+}
+
+func (b *BeanValidated) Validate() error {
+	if b.Name == "" {
+		return property.ValidationErrors{
+			{
+				Key:     "name",
+				Message: "is empty",
+			},
+		}
+	}
+	return nil
+}
+
+func TestGetBeanWithValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	f := NewBeanFactory()
+	err := f.RegisterBeanDefinition("bean", &BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanValidated)(nil)),
+		fieldDescriptors: []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "Port",
+				Typ:        reflect.TypeOf(int(0)),
+				Property: &PropertyFieldDescriptor{
+					Name:     "port",
+					Validate: "required,min=1",
+				},
+			},
+			{
+				FieldIndex: 1,
+				Name:       "Name",
+				Typ:        reflect.TypeOf(""),
+				Property: &PropertyFieldDescriptor{
+					Name:     "name",
+					Validate: "max=3",
+				},
+			},
+		},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = f.GetBean("bean")
+	g.Expect(err).To(HaveOccurred())
+	var errs property.ValidationErrors
+	g.Expect(xerrors.As(err, &errs)).To(BeTrue())
+	g.Expect([]property.ValidationError(errs)).To(Equal([]property.ValidationError{
+		{
+			Key:     "port",
+			Message: "is required",
+		},
+		{
+			Key:     "name",
+			Message: "is empty",
+		},
+	}))
+
+	g.Expect(f.Set("port", 0)).ToNot(HaveOccurred())
+	g.Expect(f.Set("name", "nuwa")).ToNot(HaveOccurred())
+	_, err = f.GetBean("bean")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot validate bean 'bean': 2 validation errors: property 'port' value must be greater than or equal to 1, got 0; property 'name' length must be less than or equal to 3, got 4"))
+
+	g.Expect(f.Set("port", 80)).ToNot(HaveOccurred())
+	g.Expect(f.Set("name", "app")).ToNot(HaveOccurred())
+	obj, err := f.GetBean("bean")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(Equal(&BeanValidated{
+		Port: 80,
+		Name: "app",
+	}))
+}
+
+var destroyedBeans []string
+
+type BeanDisposable struct {
+	V int
+}
+
+func (b *BeanDisposable) Destroy() error {
+	destroyedBeans = append(destroyedBeans, "disposable")
+	return nil
+}
+
+type BeanWithDestroyMethod struct {
+	B *BeanDisposable
+}
+
+func (b *BeanWithDestroyMethod) Close() {
+	destroyedBeans = append(destroyedBeans, "closer")
+}
+
+type BeanRefreshDisposable struct {
+	DB map[string]string
+}
+
+func (b *BeanRefreshDisposable) Destroy() error {
+	destroyedBeans = append(destroyedBeans, "refresh:"+b.DB["host"])
+	return nil
+}
+
+func TestRefreshBeanWithSubKey(t *testing.T) {
+	g := NewWithT(t)
+
+	destroyedBeans = nil
+	f := NewBeanFactory()
+	err := f.RegisterBeanDefinition("refresh", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanRefreshDisposable)(nil)),
+		fieldDescriptors: []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "DB",
+				Typ:        reflect.TypeOf(map[string]string{}),
+				Property: &PropertyFieldDescriptor{
+					Name: "db",
+				},
+			},
+		},
+	}).SetScope(ScopeRefresh))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.Set("db.host", "a")).ToNot(HaveOccurred())
+
+	refresh, err := f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(refresh.(*BeanRefreshDisposable).DB).To(Equal(map[string]string{"host": "a"}))
+
+	// The key which is not the sub key of property is ignored
+	g.Expect(f.Set("dbx", "x")).ToNot(HaveOccurred())
+	obj, err := f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(refresh))
+	g.Expect(destroyedBeans).To(BeEmpty())
+
+	// The sub key changed will rebuild the bean, and the old instance is destroyed
+	g.Expect(f.Set("db.host", "b")).ToNot(HaveOccurred())
+	f.(*beanFactoryImpl).destroying.Wait()
+	g.Expect(destroyedBeans).To(Equal([]string{"refresh:a"}))
+	obj, err = f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(refresh))
+	g.Expect(obj.(*BeanRefreshDisposable).DB).To(Equal(map[string]string{"host": "b"}))
+}
+
+type BeanRefreshHolder struct {
+	R *BeanRefreshDisposable
+}
+
+func TestRefreshBeanReferenced(t *testing.T) {
+	g := NewWithT(t)
+
+	destroyedBeans = nil
+	f := NewBeanFactory()
+	err := f.RegisterBeanDefinition("refresh", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanRefreshDisposable)(nil)),
+		fieldDescriptors: []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "DB",
+				Typ:        reflect.TypeOf(map[string]string{}),
+				Property: &PropertyFieldDescriptor{
+					Name: "db",
+				},
+			},
+		},
+	}).SetScope(ScopeRefresh))
+	g.Expect(err).ToNot(HaveOccurred())
+	err = f.RegisterBeanDefinition("holder", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanRefreshHolder)(nil)),
+		fieldDescriptors: []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "R",
+				Typ:        reflect.TypeOf((*BeanRefreshDisposable)(nil)),
+				Bean: &BeanFieldDescriptor{
+					Name: "refresh",
+				},
+			},
+		},
+	}).SetScope(ScopeSingleton))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.Set("db.host", "a")).ToNot(HaveOccurred())
+
+	holder, err := f.GetBean("holder")
+	g.Expect(err).ToNot(HaveOccurred())
+	refresh := holder.(*BeanRefreshHolder).R
+	g.Expect(refresh.DB).To(Equal(map[string]string{"host": "a"}))
+
+	// The referenced instance is kept alive for the holder, only the new lookup is rebuilt
+	g.Expect(f.Set("db.host", "b")).ToNot(HaveOccurred())
+	f.(*beanFactoryImpl).destroying.Wait()
+	g.Expect(destroyedBeans).To(BeEmpty())
+	g.Expect(holder.(*BeanRefreshHolder).R).To(BeIdenticalTo(refresh))
+	obj, err := f.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(refresh))
+	g.Expect(obj.(*BeanRefreshDisposable).DB).To(Equal(map[string]string{"host": "b"}))
+}
+
+func TestDestroySingletons(t *testing.T) {
+	g := NewWithT(t)
+
+	register := func(f BeanFactory) {
+		err := f.RegisterBeanDefinition("disposable", (&BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*BeanDisposable)(nil)),
+		}).SetScope(ScopeSingleton))
+		g.Expect(err).ToNot(HaveOccurred())
+		err = f.RegisterBeanDefinition("closer", (&BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*BeanWithDestroyMethod)(nil)),
+			fieldDescriptors: []FieldDescriptor{
+				{
+					FieldIndex: 0,
+					Name:       "B",
+					Typ:        reflect.TypeOf((*BeanDisposable)(nil)),
+					Bean: &BeanFieldDescriptor{
+						Name: "disposable",
+					},
+				},
+			},
+		}).SetScope(ScopeSingleton).SetDestroyMethodName("Close"))
+		g.Expect(err).ToNot(HaveOccurred())
+		err = f.RegisterBeanDefinition("invalid", (&BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		}).SetScope(ScopeSingleton).SetDestroyMethodName("Missing"))
+		g.Expect(err).ToNot(HaveOccurred())
+		err = f.RegisterBeanDefinition("prototype", (&BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*BeanDisposable)(nil)),
+		}).SetScope(ScopePrototype))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		for _, name := range []string{"invalid", "closer", "prototype"} {
+			_, err = f.GetBean(name)
+			g.Expect(err).ToNot(HaveOccurred())
+		}
+	}
+
+	destroyedBeans = nil
+	f := NewBeanFactory()
+	register(f)
+	err := f.DestroySingletons(context.Background())
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot destroy singletons: Cannot destroy bean 'invalid', the destroy method 'Missing' must be func() or func() error"))
+	g.Expect(destroyedBeans).To(Equal([]string{"closer", "disposable"}))
+
+	// The destroyed instances are removed
+	err = f.DestroySingletons(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(destroyedBeans).To(Equal([]string{"closer", "disposable"}))
+
+	destroyedBeans = nil
+	f = NewBeanFactory()
+	register(f)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = f.DestroySingletons(ctx)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot destroy singletons: beans [invalid disposable closer] are not destroyed: context canceled"))
+	g.Expect(destroyedBeans).To(BeEmpty())
+}
+
+func TestChildBeanFactory(t *testing.T) {
+	g := NewWithT(t)
+
+	propertyField := func(name string) []FieldDescriptor {
+		return []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "V",
+				Typ:        reflect.TypeOf(int(0)),
+				Property: &PropertyFieldDescriptor{
+					Name: name,
+				},
+			},
+		}
+	}
+	register := func(f BeanFactory, name string, beanDefinition BeanDefinition) {
+		err := f.RegisterBeanDefinition(name, beanDefinition)
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	parent := NewBeanFactory()
+	register(parent, "disposable", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanDisposable)(nil)),
+	}).SetScope(ScopeSingleton))
+	register(parent, "shared", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField("val"),
+	}).SetScope(ScopeSingleton))
+	register(parent, "parentOnly", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField("val"),
+	}).SetScope(ScopeSingleton))
+	g.Expect(parent.Set("val", 100)).ToNot(HaveOccurred())
+	g.Expect(parent.Set("other", 1)).ToNot(HaveOccurred())
+
+	child := NewChildBeanFactory(parent)
+	g.Expect(child.ParentBeanFactory()).To(BeIdenticalTo(parent))
+	register(child, "closer", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanWithDestroyMethod)(nil)),
+		fieldDescriptors: []FieldDescriptor{
+			{
+				FieldIndex: 0,
+				Name:       "B",
+				Typ:        reflect.TypeOf((*BeanDisposable)(nil)),
+				Bean: &BeanFieldDescriptor{
+					Name: "disposable",
+				},
+			},
+		},
+	}).SetScope(ScopeSingleton).SetDestroyMethodName("Close"))
+	register(child, "shared", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField("val"),
+	}).SetScope(ScopeSingleton))
+	register(child, "refresh", (&BeanDefinitionImpl{
+		Typ:              reflect.TypeOf((*BeanOnlyPropertyField)(nil)),
+		fieldDescriptors: propertyField("other"),
+	}).SetScope(ScopeRefresh))
+	g.Expect(child.Set("val", 200)).ToNot(HaveOccurred())
+
+	// The properties of child hide the ones of parent
+	v, err := child.Get("val")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("200"))
+	v, err = parent.Get("val")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("100"))
+
+	// The beans of parent are shared with child
+	disposable, err := parent.GetBean("disposable")
+	g.Expect(err).ToNot(HaveOccurred())
+	closer, err := child.GetBean("closer")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(closer.(*BeanWithDestroyMethod).B).To(BeIdenticalTo(disposable))
+	obj, err := child.GetBean("disposable")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(disposable))
+	g.Expect(child.ContainsBean("disposable")).To(BeTrue())
+	g.Expect(parent.ContainsBean("closer")).To(BeFalse())
+	_, err = parent.GetBean("closer")
+	g.Expect(err).To(HaveOccurred())
+
+	// The beans of child hide the ones of parent
+	obj, err = child.GetBean("shared")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj.(*BeanOnlyPropertyField).V).To(Equal(200))
+	obj, err = parent.GetBean("shared")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj.(*BeanOnlyPropertyField).V).To(Equal(100))
+	g.Expect(child.GetBeanNamesForType(reflect.TypeOf((*BeanOnlyPropertyField)(nil)))).To(Equal([]string{"shared", "refresh", "parentOnly"}))
+	beans := []*BeanOnlyPropertyField{}
+	g.Expect(child.RetriveBeans(&beans)).ToNot(HaveOccurred())
+	g.Expect(beans).To(HaveLen(3))
+
+	// The refresh bean of child is rebuilt when the properties of parent are changed
+	refresh, err := child.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(refresh.(*BeanOnlyPropertyField).V).To(Equal(1))
+	g.Expect(parent.Set("other", 2)).ToNot(HaveOccurred())
+	obj, err = child.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(refresh))
+	g.Expect(obj.(*BeanOnlyPropertyField).V).To(Equal(2))
+
+	// The child is destroyed independently
+	child.(*beanFactoryImpl).destroying.Wait()
+	destroyedBeans = nil
+	g.Expect(child.DestroySingletons(context.Background())).ToNot(HaveOccurred())
+	g.Expect(destroyedBeans).To(Equal([]string{"closer"}))
+	obj, err = parent.GetBean("disposable")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(disposable))
+
+	// The closed child doesn't observe the changes of parent
+	refresh, err = child.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	child.Close()
+	g.Expect(parent.Set("other", 3)).ToNot(HaveOccurred())
+	obj, err = child.GetBean("refresh")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(refresh))
+}
+
+func TestChildBeanFactoryWithInactiveBean(t *testing.T) {
+	g := NewWithT(t)
+
+	parent := NewBeanFactory()
+	err := parent.RegisterBeanDefinition("x", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanDisposable)(nil)),
+	}).SetScope(ScopeSingleton))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	child := NewChildBeanFactory(parent)
+	for _, name := range []string{"x", "y"} {
+		err = child.RegisterBeanDefinition(name, (&BeanDefinitionImpl{
+			Typ: reflect.TypeOf((*BeanDisposable)(nil)),
+		}).SetScope(ScopeSingleton).SetProfiles("nope"))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	// The inactive bean definition of child doesn't hide the bean of parent
+	expected, err := parent.GetBean("x")
+	g.Expect(err).ToNot(HaveOccurred())
+	obj, err := child.GetBean("x")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).To(BeIdenticalTo(expected))
+	g.Expect(child.ContainsBean("x")).To(BeTrue())
+	g.Expect(child.GetBeanNamesForType(reflect.TypeOf((*BeanDisposable)(nil)))).To(Equal([]string{"x"}))
+
+	_, err = child.GetBean("y")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("Bean 'y' is not active"))
+	g.Expect(child.ContainsBean("y")).To(BeFalse())
+
+	child.SetActiveProfiles("nope")
+	obj, err = child.GetBean("x")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(obj).ToNot(BeIdenticalTo(expected))
+}
+
+func TestBeanFactoryDumpSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := property.NewAESGCMCipher([]byte("0123456789abcdef"))
+	g.Expect(err).ToNot(HaveOccurred())
+	ciphertext, err := c.Encrypt("s3cr3t-plain")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := property.NewCompositeProperties(property.NewProperties())
+	p.SetDecryptor(c)
+	g.Expect(p.Set("db.url", "ENC("+ciphertext+")")).ToNot(HaveOccurred())
+	g.Expect(p.Set("db.dsn", "pg://${db.url}@localhost")).ToNot(HaveOccurred())
+	f := NewBeanFactoryWithProperties(p)
+
+	// The raw values are passed through the factory, so the secrets are masked
+	v, err := f.Get("db.url")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("s3cr3t-plain"))
+	buf := &bytes.Buffer{}
+	g.Expect(property.Dump(buf, f)).ToNot(HaveOccurred())
+	g.Expect(buf.String()).ToNot(ContainSubstring("s3cr3t-plain"))
+	values := map[string]string{}
+	for _, entry := range property.Entries(f) {
+		values[entry.Key] = entry.Value
+	}
+	g.Expect(values).To(Equal(map[string]string{
+		"db.url": property.MaskedValue,
+		"db.dsn": "pg://${db.url}@localhost",
+	}))
+}
+
+func TestChildBeanFactoryRawValues(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := property.NewAESGCMCipher([]byte("0123456789abcdef"))
+	g.Expect(err).ToNot(HaveOccurred())
+	ciphertext, err := c.Encrypt("s3cr3t-plain")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := property.NewCompositeProperties(property.NewProperties())
+	g.Expect(p.Set("db.url", "ENC("+ciphertext+")")).ToNot(HaveOccurred())
+	g.Expect(p.Set("template", "$${literal}")).ToNot(HaveOccurred())
+	parent := NewBeanFactoryWithProperties(p)
+	child := NewChildBeanFactory(parent)
+	defer child.Close()
+
+	_, err = child.Get("db.url")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("no decryptor"))
+
+	// The Decryptor of parent is used even if it's set after the child is created
+	p.SetDecryptor(c)
+	for _, f := range []BeanFactory{parent, child} {
+		v, err := f.Get("db.url")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(v).To(Equal("s3cr3t-plain"))
+		v, err = f.Get("template")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(v).To(Equal("${literal}"))
+
+		buf := &bytes.Buffer{}
+		g.Expect(property.Dump(buf, f)).ToNot(HaveOccurred())
+		g.Expect(buf.String()).ToNot(ContainSubstring("s3cr3t-plain"))
+
+		var n int
+		err = f.Retrive("db.url", &n)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).ToNot(ContainSubstring("s3cr3t-plain"))
+	}
+}
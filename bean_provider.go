@@ -0,0 +1,17 @@
+package nuwa
+
+// BeanProvider provide the instance of bean, the instance returned may be different
+// if the bean is refreshed.
+type BeanProvider interface {
+	// Get return the current instance of bean.
+	Get() (interface{}, error)
+}
+
+type beanProviderImpl struct {
+	name    string
+	factory BeanFactory
+}
+
+func (p *beanProviderImpl) Get() (interface{}, error) {
+	return p.factory.GetBean(p.name)
+}
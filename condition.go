@@ -0,0 +1,135 @@
+package nuwa
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lsytj0413/nuwa/property"
+)
+
+// Condition determine whether the bean definition should been active.
+type Condition func(ctx ConditionContext) ConditionOutcome
+
+// ConditionOutcome is the outcome of condition, the Message explain why the
+// condition is matched or not.
+type ConditionOutcome struct {
+	Match   bool
+	Message string
+}
+
+// ConditionContext is the context for the evaluation of condition.
+type ConditionContext interface {
+	property.Properties
+
+	// ContainsBean return true if the bean with name is active.
+	ContainsBean(name string) bool
+
+	// ContainsBeanOfType return true if any active bean is assignable to typ.
+	ContainsBeanOfType(typ reflect.Type) bool
+}
+
+// ConditionEvaluation is the evaluation report for bean definition.
+type ConditionEvaluation struct {
+	BeanName string
+	Match    bool
+	Outcomes []ConditionOutcome
+}
+
+// OnProperty return the Condition which match if the property with name is equal to
+// havingValue. If the havingValue is empty, it will match if the property is exists and
+// not equal to "false".
+func OnProperty(name string, havingValue string) Condition {
+	return func(ctx ConditionContext) ConditionOutcome {
+		val, err := ctx.Get(name)
+		if err != nil {
+			return ConditionOutcome{
+				Match:   false,
+				Message: fmt.Sprintf("OnProperty: property '%v' not found", name),
+			}
+		}
+
+		match := val == havingValue
+		if havingValue == "" {
+			match = val != "false"
+		}
+		return ConditionOutcome{
+			Match:   match,
+			Message: fmt.Sprintf("OnProperty: property '%v' is '%v', expect '%v'", name, val, havingValue),
+		}
+	}
+}
+
+// OnBean return the Condition which match if the bean with name is active.
+func OnBean(name string) Condition {
+	return func(ctx ConditionContext) ConditionOutcome {
+		if ctx.ContainsBean(name) {
+			return ConditionOutcome{
+				Match:   true,
+				Message: fmt.Sprintf("OnBean: found bean '%v'", name),
+			}
+		}
+
+		return ConditionOutcome{
+			Match:   false,
+			Message: fmt.Sprintf("OnBean: bean '%v' not found", name),
+		}
+	}
+}
+
+// OnMissingBean return the Condition which match if no active bean is assignable to typ.
+func OnMissingBean(typ reflect.Type) Condition {
+	return func(ctx ConditionContext) ConditionOutcome {
+		if ctx.ContainsBeanOfType(typ) {
+			return ConditionOutcome{
+				Match:   false,
+				Message: fmt.Sprintf("OnMissingBean: found bean of type '%v'", typ),
+			}
+		}
+
+		return ConditionOutcome{
+			Match:   true,
+			Message: fmt.Sprintf("OnMissingBean: bean of type '%v' not found", typ),
+		}
+	}
+}
+
+// isAssignableBeanType return true if the bean of beanType can been assigned to typ
+func isAssignableBeanType(beanType reflect.Type, typ reflect.Type) bool {
+	if typ.Kind() == reflect.Interface {
+		return beanType.Implements(typ)
+	}
+	return beanType == typ
+}
+
+type conditionContext struct {
+	property.Properties
+
+	// parent is the parent factory, the beans of parent are visible to conditions
+	parent          BeanFactory
+	activeBeanNames []string
+	activeBeans     map[string]BeanDefinition
+}
+
+func (c *conditionContext) ContainsBean(name string) bool {
+	return c.isActive(name) || (c.parent != nil && c.parent.ContainsBean(name))
+}
+
+// isActive return true if the bean definition of name is active, the parent is ignored
+func (c *conditionContext) isActive(name string) bool {
+	_, ok := c.activeBeans[name]
+	return ok
+}
+
+func (c *conditionContext) ContainsBeanOfType(typ reflect.Type) bool {
+	for _, beanDefinition := range c.activeBeans {
+		if isAssignableBeanType(beanDefinition.Type(), typ) {
+			return true
+		}
+	}
+	return c.parent != nil && len(c.parent.GetBeanNamesForType(typ)) != 0
+}
+
+func (c *conditionContext) activate(name string, beanDefinition BeanDefinition) {
+	c.activeBeanNames = append(c.activeBeanNames, name)
+	c.activeBeans[name] = beanDefinition
+}
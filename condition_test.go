@@ -0,0 +1,238 @@
+package nuwa
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+type conditionTestCache interface {
+	Name() string
+}
+
+type conditionTestDefaultCache struct{}
+
+func (c *conditionTestDefaultCache) Name() string {
+	return "default"
+}
+
+type conditionTestUserCache struct{}
+
+func (c *conditionTestUserCache) Name() string {
+	return "user"
+}
+
+func TestConditions(t *testing.T) {
+	cacheType := reflect.TypeOf((*conditionTestCache)(nil)).Elem()
+	type testCase struct {
+		desp        string
+		register    func(f BeanFactory) error
+		properties  map[string]string
+		expect      []string
+		evaluations []ConditionEvaluation
+	}
+	testCases := []testCase{
+		{
+			desp: "default bean on missing bean",
+			register: func(f BeanFactory) error {
+				return f.RegisterBeanDefinition("defaultCache", (&BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestDefaultCache)(nil)),
+				}).SetConditions(OnMissingBean(cacheType)))
+			},
+			expect: []string{"default"},
+			evaluations: []ConditionEvaluation{
+				{
+					BeanName: "defaultCache",
+					Match:    true,
+					Outcomes: []ConditionOutcome{
+						{
+							Match:   true,
+							Message: "OnMissingBean: bean of type 'nuwa.conditionTestCache' not found",
+						},
+					},
+				},
+			},
+		},
+		{
+			desp: "default bean overridden by user bean",
+			register: func(f BeanFactory) error {
+				err := f.RegisterBeanDefinition("defaultCache", (&BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestDefaultCache)(nil)),
+				}).SetConditions(OnMissingBean(cacheType)))
+				if err != nil {
+					return err
+				}
+				return f.RegisterBeanDefinition("userCache", &BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestUserCache)(nil)),
+				})
+			},
+			expect: []string{"user"},
+			evaluations: []ConditionEvaluation{
+				{
+					BeanName: "userCache",
+					Match:    true,
+				},
+				{
+					BeanName: "defaultCache",
+					Match:    false,
+					Outcomes: []ConditionOutcome{
+						{
+							Match:   false,
+							Message: "OnMissingBean: found bean of type 'nuwa.conditionTestCache'",
+						},
+					},
+				},
+			},
+		},
+		{
+			desp: "on property and on bean",
+			register: func(f BeanFactory) error {
+				err := f.RegisterBeanDefinition("defaultCache", (&BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestDefaultCache)(nil)),
+				}).SetConditions(OnProperty("cache.enabled", "true")))
+				if err != nil {
+					return err
+				}
+				return f.RegisterBeanDefinition("userCache", (&BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestUserCache)(nil)),
+				}).SetConditions(OnBean("defaultCache")))
+			},
+			properties: map[string]string{
+				"cache.enabled": "false",
+			},
+			expect: []string{},
+			evaluations: []ConditionEvaluation{
+				{
+					BeanName: "defaultCache",
+					Match:    false,
+					Outcomes: []ConditionOutcome{
+						{
+							Match:   false,
+							Message: "OnProperty: property 'cache.enabled' is 'false', expect 'true'",
+						},
+					},
+				},
+				{
+					BeanName: "userCache",
+					Match:    false,
+					Outcomes: []ConditionOutcome{
+						{
+							Match:   false,
+							Message: "OnBean: bean 'defaultCache' not found",
+						},
+					},
+				},
+			},
+		},
+		{
+			desp: "custom condition",
+			register: func(f BeanFactory) error {
+				return f.RegisterBeanDefinition("userCache", (&BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*conditionTestUserCache)(nil)),
+				}).SetConditions(
+					OnProperty("cache.enabled", ""),
+					func(ctx ConditionContext) ConditionOutcome {
+						return ConditionOutcome{
+							Match:   !ctx.ContainsBean("userCache"),
+							Message: "custom",
+						}
+					},
+				))
+			},
+			properties: map[string]string{
+				"cache.enabled": "yes",
+			},
+			expect: []string{"user"},
+			evaluations: []ConditionEvaluation{
+				{
+					BeanName: "userCache",
+					Match:    true,
+					Outcomes: []ConditionOutcome{
+						{
+							Match:   true,
+							Message: "OnProperty: property 'cache.enabled' is 'yes', expect ''",
+						},
+						{
+							Match:   true,
+							Message: "custom",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			f := NewBeanFactory()
+			g.Expect(tc.register(f)).ToNot(HaveOccurred())
+			for k, v := range tc.properties {
+				g.Expect(f.Set(k, v)).ToNot(HaveOccurred())
+			}
+
+			caches := []conditionTestCache{}
+			err := f.RetriveBeans(&caches)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			actual := []string{}
+			for _, c := range caches {
+				actual = append(actual, c.Name())
+			}
+			g.Expect(actual).To(Equal(tc.expect))
+			g.Expect(f.ConditionReport()).To(Equal(tc.evaluations))
+		})
+	}
+}
+
+func TestConditionsEvaluatedOnce(t *testing.T) {
+	g := NewWithT(t)
+
+	evaluated := 0
+	counted := func(ctx ConditionContext) ConditionOutcome {
+		evaluated++
+		_, err := ctx.Get("cache.enabled")
+		return ConditionOutcome{
+			Match:   err == nil,
+			Message: "counted",
+		}
+	}
+
+	f := NewBeanFactory()
+	g.Expect(f.Set("cache.enabled", "true")).ToNot(HaveOccurred())
+	err := f.RegisterBeanDefinition("userCache", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*conditionTestUserCache)(nil)),
+	}).SetConditions(counted))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cacheType := reflect.TypeOf((*conditionTestCache)(nil)).Elem()
+	_, err = f.GetBean("userCache")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.ContainsBean("userCache")).To(BeTrue())
+	g.Expect(f.GetBeanNamesForType(cacheType)).To(Equal([]string{"userCache"}))
+	g.Expect(f.ConditionReport()).To(HaveLen(1))
+	g.Expect(evaluated).To(Equal(1))
+
+	// The changes of properties don't deactivate the evaluated beans
+	g.Expect(f.Unset("cache.enabled")).ToNot(HaveOccurred())
+	g.Expect(f.ContainsBean("userCache")).To(BeTrue())
+	g.Expect(evaluated).To(Equal(1))
+
+	// The registration invalidates the evaluation
+	err = f.RegisterBeanDefinition("defaultCache", &BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*conditionTestDefaultCache)(nil)),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.ContainsBean("userCache")).To(BeFalse())
+	g.Expect(evaluated).To(Equal(2))
+
+	// So does the removal and the change of active profiles
+	g.Expect(f.Set("cache.enabled", "true")).ToNot(HaveOccurred())
+	g.Expect(f.RemoveBeanDefinition("defaultCache")).ToNot(HaveOccurred())
+	g.Expect(f.ContainsBean("userCache")).To(BeTrue())
+	g.Expect(evaluated).To(Equal(3))
+	f.SetActiveProfiles("dev")
+	g.Expect(f.ContainsBean("userCache")).To(BeTrue())
+	g.Expect(evaluated).To(Equal(4))
+}
@@ -0,0 +1,7 @@
+package nuwa
+
+// DisposableBean is to be implemented by beans that want to release resources on destruction.
+type DisposableBean interface {
+	// Destroy is invoked when the shared bean instance is destroyed by the bean factory.
+	Destroy() error
+}
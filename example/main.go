@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"reflect"
 
 	"github.com/lsytj0413/nuwa"
@@ -24,17 +25,28 @@ func (r *Runner2) Run(ctx context.Context) {
 }
 
 func main() {
-	ap := app.NewApplication()
-
-	ap.RegisterBeanDefinition("runner1", &nuwa.BeanDefinitionImpl{
-		Typ: reflect.TypeOf((*Runner1)(nil)),
-	})
-	ap.RegisterBeanDefinition("runner2", &nuwa.BeanDefinitionImpl{
-		Typ: reflect.TypeOf((*Runner2)(nil)),
-	})
-
-	err := ap.Run()
+	ap, err := app.New(
+		app.WithEnvPrefix("NUWA"),
+		app.WithArgs(os.Args[1:]),
+		app.WithBeans(
+			app.Bean{
+				Name: "runner1",
+				Definition: &nuwa.BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*Runner1)(nil)),
+				},
+			},
+			app.Bean{
+				Name: "runner2",
+				Definition: &nuwa.BeanDefinitionImpl{
+					Typ: reflect.TypeOf((*Runner2)(nil)),
+				},
+			},
+		),
+	)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "Cannot create application: %v\n", err)
+		os.Exit(1)
 	}
+
+	os.Exit(app.Main(ap))
 }
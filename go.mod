@@ -6,4 +6,5 @@ require (
 	github.com/onsi/gomega v1.16.0
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cast v1.4.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,157 @@
+// Package logger provide the structured logging used by nuwa, the Logger is compatible
+// with *slog.Logger, so the application can plug in its own logging backend, eg:
+//
+//	logger.SetDefault(slog.Default())
+//
+// The default Logger discards all the records.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the importance of log record, the values are the same as slog.Level
+type Level int
+
+const (
+	// LevelDebug is the level of diagnostic records, eg: the bean creation timings
+	LevelDebug Level = -4
+	// LevelInfo is the level of normal records, eg: the shutdown reason
+	LevelInfo Level = 0
+	// LevelWarn is the level of records which may need attention, eg: the overridden registrations
+	LevelWarn Level = 4
+	// LevelError is the level of failures
+	LevelError Level = 8
+)
+
+// String return the name of level, eg: INFO
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	}
+	return "ERROR"
+}
+
+// Logger is the structured logger, the args are the alternating keys and values, eg:
+//
+//	l.Info("Bean created", "name", name, "elapsed", elapsed)
+//
+// It's implemented by *slog.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// LogFunc is the function which write the record with level, it adapts the logging
+// backend to Logger, eg: the slog.Logger.Log
+//
+//	logger.SetDefault(logger.LogFunc(func(level logger.Level, msg string, args ...interface{}) {
+//		l.Log(context.Background(), slog.Level(level), msg, args...)
+//	}))
+type LogFunc func(level Level, msg string, args ...interface{})
+
+// Debug log the record with LevelDebug
+func (f LogFunc) Debug(msg string, args ...interface{}) {
+	f(LevelDebug, msg, args...)
+}
+
+// Info log the record with LevelInfo
+func (f LogFunc) Info(msg string, args ...interface{}) {
+	f(LevelInfo, msg, args...)
+}
+
+// Warn log the record with LevelWarn
+func (f LogFunc) Warn(msg string, args ...interface{}) {
+	f(LevelWarn, msg, args...)
+}
+
+// Error log the record with LevelError
+func (f LogFunc) Error(msg string, args ...interface{}) {
+	f(LevelError, msg, args...)
+}
+
+// NewNopLogger return the Logger which discard all the records
+func NewNopLogger() Logger {
+	return LogFunc(func(level Level, msg string, args ...interface{}) {})
+}
+
+// NewTextLogger return the Logger which write the records with level >= minLevel to w,
+// one line per record in form of: time level msg key=value...
+func NewTextLogger(w io.Writer, minLevel Level) Logger {
+	var lock sync.Mutex
+	return LogFunc(func(level Level, msg string, args ...interface{}) {
+		if level < minLevel {
+			return
+		}
+
+		var sb strings.Builder
+		sb.WriteString(time.Now().Format(time.RFC3339))
+		sb.WriteString(" ")
+		sb.WriteString(level.String())
+		sb.WriteString(" ")
+		sb.WriteString(msg)
+		for i := 0; i < len(args); i += 2 {
+			key, val := fmt.Sprint(args[i]), interface{}("")
+			if i+1 < len(args) {
+				val = args[i+1]
+			} else {
+				key, val = "!BADKEY", args[i]
+			}
+			sb.WriteString(" ")
+			sb.WriteString(key)
+			sb.WriteString("=")
+			sb.WriteString(formatValue(val))
+		}
+		sb.WriteString("\n")
+
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = io.WriteString(w, sb.String())
+	})
+}
+
+// formatValue return the text of val, it's quoted if contains space or quote
+func formatValue(val interface{}) string {
+	s := fmt.Sprint(val)
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// holder hold the default Logger, the atomic.Value requires the same concrete type
+type holder struct {
+	Logger
+}
+
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(holder{NewNopLogger()})
+}
+
+// SetDefault set the Logger used by nuwa, the nil will reset it to the no-op Logger.
+func SetDefault(l Logger) {
+	if l == nil {
+		l = NewNopLogger()
+	}
+	defaultLogger.Store(holder{l})
+}
+
+// Default return the Logger used by nuwa
+func Default() Logger {
+	return defaultLogger.Load().(holder).Logger
+}
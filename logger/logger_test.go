@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	// NOTE: the dot import of gomega conflicts with Default of this package
+	"github.com/onsi/gomega"
+)
+
+func TestTextLogger(t *testing.T) {
+	type testCase struct {
+		desp   string
+		log    func(l Logger)
+		expect string
+	}
+	testCases := []testCase{
+		{
+			desp: "info with args",
+			log: func(l Logger) {
+				l.Info("Bean created", "name", "db", "scope", "singleton")
+			},
+			expect: "INFO Bean created name=db scope=singleton\n",
+		},
+		{
+			desp: "quoted value",
+			log: func(l Logger) {
+				l.Error("Application failed", "err", errors.New("connection refused"))
+			},
+			expect: "ERROR Application failed err=\"connection refused\"\n",
+		},
+		{
+			desp: "missing value",
+			log: func(l Logger) {
+				l.Warn("Application force quit", "interrupt")
+			},
+			expect: "WARN Application force quit !BADKEY=interrupt\n",
+		},
+		{
+			desp: "filtered by level",
+			log: func(l Logger) {
+				l.Debug("Bean destroyed", "name", "db")
+			},
+			expect: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+
+			buf := &bytes.Buffer{}
+			tc.log(NewTextLogger(buf, LevelInfo))
+			if tc.expect == "" {
+				g.Expect(buf.String()).To(gomega.BeEmpty())
+				return
+			}
+
+			// The line is started with time
+			idx := strings.Index(buf.String(), " ")
+			g.Expect(idx).To(gomega.BeNumerically(">", 0))
+			g.Expect(buf.String()[idx+1:]).To(gomega.Equal(tc.expect))
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	g := gomega.NewWithT(t)
+	defer SetDefault(nil)
+
+	records := []string{}
+	SetDefault(LogFunc(func(level Level, msg string, args ...interface{}) {
+		records = append(records, level.String()+" "+msg)
+	}))
+	Default().Debug("a")
+	Default().Info("b")
+	Default().Warn("c")
+	Default().Error("d")
+	g.Expect(records).To(gomega.Equal([]string{"DEBUG a", "INFO b", "WARN c", "ERROR d"}))
+
+	SetDefault(nil)
+	Default().Info("e")
+	g.Expect(records).To(gomega.HaveLen(4))
+}
@@ -0,0 +1,52 @@
+package nuwa
+
+import (
+	"strings"
+)
+
+// ProfilesActivePropertyName is the property to specify the active profiles,
+// multiple profiles are separated by comma, eg: dev,mysql
+const ProfilesActivePropertyName = "nuwa.profiles.active"
+
+// ParseProfiles split the comma separated profiles, the empty profile is ignored.
+func ParseProfiles(s string) []string {
+	ret := []string{}
+	for _, profile := range strings.Split(s, ",") {
+		profile = strings.TrimSpace(profile)
+		if profile != "" {
+			ret = append(ret, profile)
+		}
+	}
+	return ret
+}
+
+// AcceptsProfiles return true if any of the profiles is accepted by the activeProfiles.
+// The profile with prefix '!' is accepted if the profile is not active, and the empty
+// profiles is always accepted.
+func AcceptsProfiles(activeProfiles []string, profiles []string) bool {
+	if len(profiles) == 0 {
+		return true
+	}
+
+	isActive := func(profile string) bool {
+		for _, activeProfile := range activeProfiles {
+			if activeProfile == profile {
+				return true
+			}
+		}
+		return false
+	}
+	for _, profile := range profiles {
+		if strings.HasPrefix(profile, "!") {
+			if !isActive(profile[1:]) {
+				return true
+			}
+			continue
+		}
+
+		if isActive(profile) {
+			return true
+		}
+	}
+	return false
+}
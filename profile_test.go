@@ -0,0 +1,87 @@
+package nuwa
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestAcceptsProfiles(t *testing.T) {
+	type testCase struct {
+		desp           string
+		activeProfiles []string
+		profiles       []string
+		expect         bool
+	}
+	testCases := []testCase{
+		{
+			desp:           "empty profiles",
+			activeProfiles: []string{"dev"},
+			profiles:       nil,
+			expect:         true,
+		},
+		{
+			desp:           "match profile",
+			activeProfiles: []string{"dev", "mysql"},
+			profiles:       []string{"prod", "mysql"},
+			expect:         true,
+		},
+		{
+			desp:           "mismatch profile",
+			activeProfiles: []string{"dev"},
+			profiles:       []string{"prod"},
+			expect:         false,
+		},
+		{
+			desp:           "match negated profile",
+			activeProfiles: []string{"dev"},
+			profiles:       []string{"!prod"},
+			expect:         true,
+		},
+		{
+			desp:           "mismatch negated profile",
+			activeProfiles: []string{"prod"},
+			profiles:       []string{"!prod"},
+			expect:         false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(AcceptsProfiles(tc.activeProfiles, tc.profiles)).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestActiveProfiles(t *testing.T) {
+	g := NewWithT(t)
+
+	f := NewBeanFactory()
+	g.Expect(f.ActiveProfiles()).To(BeEmpty())
+
+	err := f.Set(ProfilesActivePropertyName, "dev, mysql,")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(f.ActiveProfiles()).To(Equal([]string{"dev", "mysql"}))
+
+	f.SetActiveProfiles("prod")
+	g.Expect(f.ActiveProfiles()).To(Equal([]string{"prod"}))
+
+	err = f.RegisterBeanDefinition("dev", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanOnlyBeanField)(nil)),
+	}).SetProfiles("dev"))
+	g.Expect(err).ToNot(HaveOccurred())
+	err = f.RegisterBeanDefinition("prod", (&BeanDefinitionImpl{
+		Typ: reflect.TypeOf((*BeanOnlyBeanField)(nil)),
+	}).SetProfiles("prod"))
+	g.Expect(err).ToNot(HaveOccurred())
+
+	_, err = f.GetBean("dev")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(MatchRegexp(`Bean 'dev' is not active: Profiles: \[dev\] not accepted by active profiles \[prod\]`))
+
+	beans := []*BeanOnlyBeanField{}
+	err = f.RetriveBeans(&beans)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(beans).To(HaveLen(1))
+}
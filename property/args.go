@@ -0,0 +1,69 @@
+package property
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// FromArgs return the Properties parsed from the command-line args, the supported forms are:
+//  1. --key=value: the key will been set to value, eg: --db.host=x or --servers[0]=a
+//  2. --key: the key will been set to "true", eg: --flag
+//
+// The args that not start with "--" are ignored, and the parse will stop at the
+// terminator "--".
+// The origin of value is the arg, and the value of secret key is masked in it, eg:
+// --db.password=******.
+func FromArgs(args []string) (Properties, error) {
+	p := newTrackedProperties()
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+
+		kv := strings.SplitN(arg[2:], "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if key == "" {
+			return nil, xerrors.Errorf("Cannot parse arg '%v', the key must not be empty", arg)
+		}
+
+		val, name := "true", arg
+		if len(kv) == 2 {
+			val = kv[1]
+			// The origin is exported with the masked value, so the secret should not leak by it
+			if IsSecretKey(key) {
+				name = "--" + kv[0] + "=" + MaskedValue
+			}
+		}
+
+		err := p.set(key, val, Origin{
+			Source: OriginSourceArgs,
+			Name:   name,
+		})
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot set arg '%v'", arg)
+		}
+	}
+
+	return p, nil
+}
+
+// FromFlagSet return the Properties with the flags of fs, only the flags which
+// have been set in the command-line are included, so the default value of flag
+// will not hide the value from other Properties.
+// NOTE: the fs must been parsed
+func FromFlagSet(fs *flag.FlagSet) Properties {
+	p := newTrackedProperties()
+	fs.Visit(func(f *flag.Flag) {
+		p.propertiesImpl[f.Name] = f.Value.String()
+		p.origins[f.Name] = Origin{
+			Source: OriginSourceFlag,
+			Name:   "-" + f.Name,
+		}
+	})
+	return p
+}
@@ -0,0 +1,97 @@
+package property
+
+import (
+	"flag"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFromArgs(t *testing.T) {
+	type testCase struct {
+		desp   string
+		args   []string
+		err    string
+		expect Properties
+	}
+	testCases := []testCase{
+		{
+			desp: "normal key value",
+			args: []string{"--db.host=x", "--servers[0]=a", "--servers[1]=b=c"},
+			err:  "",
+			expect: propertiesImpl(map[string]string{
+				"db.host":    "x",
+				"servers[0]": "a",
+				"servers[1]": "b=c",
+			}),
+		},
+		{
+			desp: "normal bool flag",
+			args: []string{"--flag", "--empty="},
+			err:  "",
+			expect: propertiesImpl(map[string]string{
+				"flag":  "true",
+				"empty": "",
+			}),
+		},
+		{
+			desp: "ignore positional and after terminator",
+			args: []string{"run", "-v", "--k1=v1", "--", "--k2=v2"},
+			err:  "",
+			expect: propertiesImpl(map[string]string{
+				"k1": "v1",
+			}),
+		},
+		{
+			desp:   "empty key",
+			args:   []string{"--=v1"},
+			err:    "Cannot parse arg '--=v1', the key must not be empty",
+			expect: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, err := FromArgs(tc.args)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual.(*trackedPropertiesImpl).propertiesImpl).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestFromArgsOrigin(t *testing.T) {
+	g := NewWithT(t)
+
+	p, err := FromArgs([]string{"--db.host=x", "--db.password=s3cr3t", "--db.token"})
+	g.Expect(err).ToNot(HaveOccurred())
+	for key, expect := range map[string]string{
+		"db.host":     "args --db.host=x",
+		"db.password": "args --db.password=******",
+		"db.token":    "args --db.token",
+	} {
+		origin, err := p.Origin(key)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(origin.String()).To(Equal(expect), key)
+	}
+}
+
+func TestFromFlagSet(t *testing.T) {
+	g := NewWithT(t)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("db.host", "localhost", "")
+	fs.Int("db.port", 3306, "")
+	err := fs.Parse([]string{"-db.port=3307"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := FromFlagSet(fs)
+	g.Expect(p.(*trackedPropertiesImpl).propertiesImpl).To(Equal(propertiesImpl(map[string]string{
+		"db.port": "3307",
+	})))
+}
@@ -0,0 +1,349 @@
+package property
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lsytj0413/nuwa/utils"
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// retrive is the implementation of Properties.Retrive, the structured target (struct, slice,
+// array and map) is bound with the sub keys of key, eg: the field Host of struct is bound
+// with key.host, the element of slice is bound with key[0]. The struct field is bound with the
+// name specified by tag `property:"name"` or the field name, which is case-insensitive.
+// The tag may specify the Hint for the field after the name, eg: `property:"maxSize,bytesize"`.
+// The embedded struct is bound with the same key as the outer struct.
+// After bound, the target is validated with the `validate` tag and the Validator interface.
+func retrive(p Properties, key string, i interface{}) error {
+	if h, ok := i.(*hintedTarget); ok {
+		vstr, err := p.Get(key)
+		if err != nil {
+			return err
+		}
+		return retriveValue(p, key, vstr, h)
+	}
+
+	v, err := utils.IndirectToSetableValue(i)
+	if err != nil {
+		return err
+	}
+
+	if !isStructuredType(v.Type()) {
+		vstr, err := p.Get(key)
+		if err != nil {
+			return err
+		}
+		return retriveValue(p, key, vstr, i)
+	}
+
+	if !hasSubKeys(p, key) {
+		if p.Has(key) {
+			return xerrors.Errorf("Cannot retrive value for key '%v', unsupported target type '%v'", key, v.Kind())
+		}
+		return xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+
+	b := &binder{
+		p:    p,
+		keys: p.Keys(),
+	}
+	err = b.bind(key, v)
+	if err != nil {
+		return err
+	}
+
+	if len(b.violations) != 0 {
+		return b.violations
+	}
+	return nil
+}
+
+// isStructuredType return true if the typ should been bound with the sub keys
+func isStructuredType(typ reflect.Type) bool {
+	if _, ok := DefaultConverterRegistry.Lookup(typ); ok {
+		return false
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		if _, ok := DefaultConverterRegistry.Lookup(typ); ok {
+			return false
+		}
+	}
+
+	switch typ {
+	case timeType, urlType, ipType, ipNetType, regexpType:
+		return false
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// subKey return the key of child, the child of root key is itself
+func subKey(key string, child string) string {
+	if key == "" {
+		return child
+	}
+	return key + "." + child
+}
+
+func hasSubKeys(p Properties, key string) bool {
+	for _, k := range p.Keys() {
+		if key == "" || (k != key && IsSubKey(k, key)) {
+			return true
+		}
+	}
+	return false
+}
+
+type binder struct {
+	p          Properties
+	keys       []string
+	violations ValidationErrors
+}
+
+// childNames return the names of direct children for key, eg: the children of db is
+// [host, port] for db.host and db.port
+func (b *binder) childNames(key string) []string {
+	prefix := key + "."
+	if key == "" {
+		prefix = ""
+	}
+
+	names := map[string]bool{}
+	for _, k := range b.keys {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		rest := k[len(prefix):]
+		if idx := strings.IndexAny(rest, ".["); idx >= 0 {
+			rest = rest[:idx]
+		}
+		if rest != "" {
+			names[rest] = true
+		}
+	}
+
+	ret := make([]string, 0, len(names))
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// elementCount return the count of elements for key, eg: 2 for key[0] and key[1], the
+// index greater than MaxArrayIndex is rejected
+func (b *binder) elementCount(key string) (int, error) {
+	count := 0
+	for _, k := range b.keys {
+		if !strings.HasPrefix(k, key+"[") {
+			continue
+		}
+
+		rest := k[len(key)+1:]
+		idx := strings.Index(rest, "]")
+		if idx < 0 {
+			continue
+		}
+		i, err := strconv.Atoi(rest[:idx])
+		if err != nil {
+			continue
+		}
+		if i > MaxArrayIndex {
+			return 0, xerrors.Errorf("Cannot retrive value for key '%v', the index %v is greater than %v", k, i, MaxArrayIndex)
+		}
+		if i+1 > count {
+			count = i + 1
+		}
+	}
+	return count, nil
+}
+
+// exists return true if the key or any of it's sub keys exists
+func (b *binder) exists(key string) bool {
+	for _, k := range b.keys {
+		if IsSubKey(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *binder) bind(key string, v reflect.Value) error {
+	if !isStructuredType(v.Type()) {
+		vstr, err := b.p.Get(key)
+		if err != nil {
+			return err
+		}
+
+		// NOTE: use the address of v, so the pointer value will not been dereferenced
+		// before the converter lookup
+		return retriveValue(b.p, key, vstr, v.Addr())
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		err := b.bindStruct(key, v)
+		if err != nil {
+			return err
+		}
+	case reflect.Slice:
+		count, err := b.elementCount(key)
+		if err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), count, count)
+		for i := 0; i < count; i++ {
+			ekey := key + "[" + strconv.Itoa(i) + "]"
+			if !b.exists(ekey) {
+				continue
+			}
+			err := b.bind(ekey, slice.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			ekey := key + "[" + strconv.Itoa(i) + "]"
+			if !b.exists(ekey) {
+				continue
+			}
+			err := b.bind(ekey, v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return xerrors.Errorf("Cannot retrive value for key '%v', the key of map '%v' must be string", key, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, name := range b.childNames(key) {
+			ev := reflect.New(v.Type().Elem()).Elem()
+			err := b.bind(subKey(key, name), ev)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), ev)
+		}
+	}
+
+	b.validate(key, v)
+	return nil
+}
+
+func (b *binder) bindStruct(key string, v reflect.Value) error {
+	names := map[string]string{}
+	for _, name := range b.childNames(key) {
+		names[strings.ToLower(name)] = name
+	}
+
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("property")
+		if tag == "-" {
+			continue
+		}
+		name, hint := parsePropertyTag(tag)
+
+		// The embedded struct is bound with the same key, the exported fields of
+		// unexported embedded struct are still setable
+		if field.Anonymous && !ok {
+			if !isStructuredType(field.Type) || (field.PkgPath != "" && field.Type.Kind() != reflect.Struct) {
+				continue
+			}
+			err := b.bind(key, v.Field(i))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			name = lowerFirst(field.Name)
+		}
+		if actual, ok := names[strings.ToLower(name)]; ok {
+			name = actual
+		}
+
+		// NOTE: the nested struct is walked even if none of it's keys is present, so the
+		// rules of it's fields are still validated
+		fkey := subKey(key, name)
+		present := b.exists(fkey)
+		if present && hint != "" {
+			err := b.bindWithHint(fkey, v.Field(i), hint)
+			if err != nil {
+				return err
+			}
+		} else if present || (field.Type.Kind() == reflect.Struct && isStructuredType(field.Type)) {
+			err := b.bind(fkey, v.Field(i))
+			if err != nil {
+				return err
+			}
+		}
+
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			b.violations = append(b.violations, validateRules(fkey, v.Field(i), present, rules, hidesValue(b.p, fkey))...)
+		}
+	}
+	return nil
+}
+
+// bindWithHint convert the value of key to v with the hint
+func (b *binder) bindWithHint(key string, v reflect.Value, hint Hint) error {
+	vstr, err := b.p.Get(key)
+	if err != nil {
+		return err
+	}
+	return retriveValue(b.p, key, vstr, WithHint(v.Addr(), hint))
+}
+
+// parsePropertyTag return the name and hint of the tag, eg: maxSize and bytesize for
+// `property:"maxSize,bytesize"`
+func parsePropertyTag(tag string) (string, Hint) {
+	idx := strings.Index(tag, ",")
+	if idx < 0 {
+		return tag, ""
+	}
+	return tag[:idx], tag[idx+1:]
+}
+
+// validate call the Validator of v, and record the violation
+func (b *binder) validate(key string, v reflect.Value) {
+	b.violations = append(b.violations, validateValidator(key, v)...)
+}
+
+// lowerFirst return the s with the first letter in lower case, eg: MaxConns to maxConns
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
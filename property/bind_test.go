@@ -0,0 +1,291 @@
+package property
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+type testServer struct {
+	Host string `validate:"required"`
+	Port int    `validate:"min=1,max=65535"`
+}
+
+type testDatabase struct {
+	Host     string
+	Port     int `property:"port" validate:"required,min=1,max=65535"`
+	MaxConns int
+	Ignored  string `property:"-"`
+}
+
+type testLimits struct {
+	Timeout time.Duration
+}
+
+type testAppConfig struct {
+	testLimits
+
+	Name     string `validate:"oneof=api worker"`
+	Database *testDatabase
+	Servers  []testServer `validate:"min=1"`
+	Tags     map[string]string
+	Ports    [2]int
+	secret   string
+}
+
+func (c *testAppConfig) Validate() error {
+	if c.Database != nil && c.Database.Host == c.Name {
+		return ValidationErrors{
+			{
+				Key:     "database.host",
+				Message: "must not be the same as name",
+			},
+		}
+	}
+	return nil
+}
+
+func TestRetriveStruct(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewProperties()
+	err := p.Set("app", map[string]interface{}{
+		"name":    "api",
+		"timeout": "3s",
+		"secret":  "x",
+		"database": map[string]interface{}{
+			"host":     "localhost",
+			"port":     3306,
+			"maxConns": 10,
+			"ignored":  "x",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{
+				"host": "a",
+				"port": 80,
+			},
+			map[string]interface{}{
+				"host": "b",
+			},
+		},
+		"tags": map[string]interface{}{
+			"env": "dev",
+		},
+		"ports": []int{8080, 8081},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := &testAppConfig{}
+	err = p.Retrive("app", c)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c).To(Equal(&testAppConfig{
+		testLimits: testLimits{
+			Timeout: 3 * time.Second,
+		},
+		Name: "api",
+		Database: &testDatabase{
+			Host:     "localhost",
+			Port:     3306,
+			MaxConns: 10,
+		},
+		Servers: []testServer{
+			{
+				Host: "a",
+				Port: 80,
+			},
+			{
+				Host: "b",
+			},
+		},
+		Tags: map[string]string{
+			"env": "dev",
+		},
+		Ports: [2]int{8080, 8081},
+	}))
+
+	db := testDatabase{}
+	err = p.Sub("app").Retrive("database", &db)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(db.Port).To(Equal(3306))
+
+	err = p.Retrive("missing", c)
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+}
+
+func TestRetriveValidation(t *testing.T) {
+	type testCase struct {
+		desp   string
+		values map[string]string
+		err    []ValidationError
+	}
+	testCases := []testCase{
+		{
+			desp: "all violations",
+			values: map[string]string{
+				"app.name":            "cron",
+				"app.database.host":   "localhost",
+				"app.servers[0].port": "0",
+				"app.servers[1].host": "b",
+				"app.servers[1].port": "65536",
+			},
+			err: []ValidationError{
+				{
+					Key:     "app.name",
+					Message: "must be one of [api worker], got 'cron'",
+				},
+				{
+					Key:     "app.database.port",
+					Message: "is required",
+				},
+				{
+					Key:     "app.servers[0].host",
+					Message: "is required",
+				},
+				{
+					Key:     "app.servers[0].port",
+					Message: "value must be greater than or equal to 1, got 0",
+				},
+				{
+					Key:     "app.servers[1].port",
+					Message: "value must be less than or equal to 65535, got 65536",
+				},
+			},
+		},
+		{
+			desp: "validator",
+			values: map[string]string{
+				"app.name":            "api",
+				"app.database.host":   "api",
+				"app.database.port":   "3306",
+				"app.servers[0].host": "a",
+			},
+			err: []ValidationError{
+				{
+					Key:     "app.database.host",
+					Message: "must not be the same as name",
+				},
+			},
+		},
+		{
+			desp: "empty slice",
+			values: map[string]string{
+				"app.name":    "worker",
+				"app.servers": "",
+			},
+			err: []ValidationError{
+				{
+					Key:     "app.servers",
+					Message: "length must be greater than or equal to 1, got 0",
+				},
+			},
+		},
+		{
+			desp: "valid",
+			values: map[string]string{
+				"app.name":            "worker",
+				"app.servers[0].host": "a",
+			},
+			err: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			p := propertiesImpl(tc.values)
+			err := p.Retrive("app", &testAppConfig{})
+			if tc.err != nil {
+				g.Expect(err).To(HaveOccurred())
+				var errs ValidationErrors
+				g.Expect(xerrors.As(err, &errs)).To(BeTrue())
+				g.Expect([]ValidationError(errs)).To(Equal(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+type testOuter struct {
+	Name   string
+	Server testServer
+}
+
+func TestRetriveNestedValidation(t *testing.T) {
+	g := NewWithT(t)
+
+	p := propertiesImpl(map[string]string{
+		"app.name": "api",
+	})
+	err := p.Retrive("app", &testOuter{})
+	g.Expect(err).To(HaveOccurred())
+	var errs ValidationErrors
+	g.Expect(xerrors.As(err, &errs)).To(BeTrue())
+	g.Expect([]ValidationError(errs)).To(Equal([]ValidationError{
+		{
+			Key:     "app.server.host",
+			Message: "is required",
+		},
+	}))
+
+	p = propertiesImpl(map[string]string{
+		"app.name":        "api",
+		"app.server.host": "localhost",
+	})
+	v := &testOuter{}
+	g.Expect(p.Retrive("app", v)).ToNot(HaveOccurred())
+	g.Expect(v.Server.Host).To(Equal("localhost"))
+}
+
+func TestRetriveLargeIndex(t *testing.T) {
+	g := NewWithT(t)
+
+	p := propertiesImpl(map[string]string{
+		"servers[1]":                 "b",
+		"hosts[9000000000000000000]": "x",
+	})
+	servers := []string{}
+	g.Expect(p.Retrive("servers", &servers)).ToNot(HaveOccurred())
+	g.Expect(servers).To(Equal([]string{"", "b"}))
+
+	hosts := []string{}
+	err := p.Retrive("hosts", &hosts)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot retrive value for key 'hosts[9000000000000000000]', the index 9000000000000000000 is greater than 65535"))
+}
+
+func TestRetriveStructWithHint(t *testing.T) {
+	g := NewWithT(t)
+
+	type testBuffer struct {
+		MaxSize  int64  `property:"maxSize,bytesize"`
+		MinSize  uint32 `property:",bytesize"`
+		Count    int
+		Overflow int8 `property:"overflow,bytesize"`
+	}
+
+	p := NewProperties()
+	err := p.Set("buffer", map[string]interface{}{
+		"maxSize": "512MiB",
+		"minSize": "4KiB",
+		"count":   "2",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var buffer testBuffer
+	err = p.Retrive("buffer", &buffer)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(buffer).To(Equal(testBuffer{
+		MaxSize: 512 * 1024 * 1024,
+		MinSize: 4 * 1024,
+		Count:   2,
+	}))
+
+	g.Expect(p.Set("buffer.overflow", "1KiB")).ToNot(HaveOccurred())
+	err = p.Retrive("buffer", &buffer)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("buffer.overflow"))
+}
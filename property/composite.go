@@ -0,0 +1,403 @@
+package property
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/lsytj0413/nuwa/logger"
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// CompositeProperties is the Properties composed by multiple Properties,
+// the former one has the higher precedence.
+// The change events of the Observable Properties are published by the CompositeProperties
+// if the changed value is not hidden by the Properties with higher precedence.
+type CompositeProperties interface {
+	Properties
+	Observable
+
+	// AddFirst add the Properties with the highest precedence.
+	AddFirst(p Properties)
+
+	// AddLast add the Properties with the lowest precedence.
+	AddLast(p Properties)
+
+	// SetDecryptor set the Decryptor for the encrypted values, the value in form of
+	// {cipher}xxx or ENC(xxx) is decrypted by Get.
+	SetDecryptor(d Decryptor)
+
+	// Decryptor return the Decryptor set by SetDecryptor, or nil if it's not set.
+	Decryptor() Decryptor
+
+	// Close remove the listeners registered with the Observable Properties, it should been
+	// called if the CompositeProperties is discarded while the Properties live longer,
+	// otherwise the CompositeProperties is retained by them.
+	Close()
+}
+
+// NewCompositeProperties return the CompositeProperties impl, the ps is ordered
+// by precedence: the value of key in ps[0] will hide the value in ps[1].
+// The Set will always store the value in the Properties with the highest precedence.
+// The placeholders in values are resolved by Get, see DefaultPlaceholderRegistry.
+func NewCompositeProperties(ps ...Properties) CompositeProperties {
+	c := newCompositeProperties()
+	for _, p := range ps {
+		c.AddLast(p)
+	}
+	return c
+}
+
+func newCompositeProperties() *compositePropertiesImpl {
+	c := &compositePropertiesImpl{}
+	c.resolver = newPlaceholderResolver(c.get)
+	c.listeners.add(c.resolver.evict)
+	return c
+}
+
+type compositePropertiesImpl struct {
+	ps        []Properties
+	decryptor Decryptor
+	resolver  *placeholderResolver
+	lock      sync.RWMutex
+
+	listeners listeners
+	// removers remove the listeners registered with the Observable Properties
+	removers []func()
+}
+
+func (c *compositePropertiesImpl) AddFirst(p Properties) {
+	c.lock.Lock()
+	existing := c.ps
+	c.ps = append([]Properties{p}, c.ps...)
+	c.lock.Unlock()
+
+	logOverrides(p, existing, "Property overridden")
+	c.observe(p)
+}
+
+func (c *compositePropertiesImpl) AddLast(p Properties) {
+	c.lock.Lock()
+	existing := c.ps
+	c.ps = append(c.ps, p)
+	c.lock.Unlock()
+
+	logOverrides(p, existing, "Property hidden")
+	c.observe(p)
+}
+
+// logOverrides log the keys of p which are also in the existing Properties, msg tell
+// whether the value of p overrides the existing value or is hidden by it.
+func logOverrides(p Properties, existing []Properties, msg string) {
+	l := logger.Default()
+	for _, key := range p.Keys() {
+		for _, e := range existing {
+			if !e.Has(key) {
+				continue
+			}
+
+			origin, _ := p.Origin(key)
+			other, _ := e.Origin(key)
+			l.Debug(msg, "key", key, "origin", origin, "other", other)
+			break
+		}
+	}
+}
+
+func (c *compositePropertiesImpl) AddChangeListener(l ChangeListener) func() {
+	return c.listeners.add(l)
+}
+
+// observe subscribe the change events of p if it's Observable.
+func (c *compositePropertiesImpl) observe(p Properties) {
+	o, ok := p.(Observable)
+	if !ok {
+		return
+	}
+
+	remove := o.AddChangeListener(func(events []ChangeEvent) {
+		c.listeners.publish(c.effectiveEvents(p, events))
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.removers = append(c.removers, remove)
+}
+
+func (c *compositePropertiesImpl) Close() {
+	c.lock.Lock()
+	removers := c.removers
+	c.removers = nil
+	c.lock.Unlock()
+
+	for _, remove := range removers {
+		remove()
+	}
+}
+
+// effectiveEvents convert the change events of p to the events of composite, the
+// event is dropped if the key is hidden by the Properties with higher precedence,
+// and the removed value is replaced by the value in the Properties with lower precedence.
+func (c *compositePropertiesImpl) effectiveEvents(p Properties, events []ChangeEvent) []ChangeEvent {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	idx := -1
+	for i := range c.ps {
+		if c.ps[i] == p {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil
+	}
+
+	ret := []ChangeEvent{}
+	for _, event := range events {
+		if _, err := getFrom(c.ps[:idx], event.Key); err == nil {
+			continue
+		}
+
+		lowerVal, lowerErr := getFrom(c.ps[idx+1:], event.Key)
+		switch event.Type {
+		case ChangeTypeAdded:
+			if lowerErr == nil {
+				event.Type = ChangeTypeModified
+				event.OldValue = lowerVal
+			}
+		case ChangeTypeRemoved:
+			if lowerErr == nil {
+				event.Type = ChangeTypeModified
+				event.NewValue = lowerVal
+			}
+		}
+		if event.Type == ChangeTypeModified && event.OldValue == event.NewValue {
+			continue
+		}
+		ret = append(ret, event)
+	}
+	return ret
+}
+
+// getFrom return the value of key from the first Properties which contains it.
+func getFrom(ps []Properties, key string) (string, error) {
+	for _, p := range ps {
+		val, err := GetRaw(p, key)
+		if err == nil {
+			return val, nil
+		}
+
+		if !xerrors.Is(err, xerrors.ErrNotFound) {
+			return "", err
+		}
+	}
+
+	return "", xerrors.WrapNotFound("property with key='%v' not found", key)
+}
+
+func (c *compositePropertiesImpl) SetDecryptor(d Decryptor) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.decryptor = d
+}
+
+func (c *compositePropertiesImpl) Decryptor() Decryptor {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.decryptor
+}
+
+func (c *compositePropertiesImpl) Get(key string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.get(key, map[string]bool{
+		key: true,
+	})
+}
+
+// get return the decrypted value of key with placeholders resolved, the visiting is
+// the keys being resolved to detect the circular reference.
+// NOTE: the c.lock must been held
+func (c *compositePropertiesImpl) get(key string, visiting map[string]bool) (string, error) {
+	val, err := getFrom(c.ps, key)
+	if err != nil {
+		return "", err
+	}
+
+	val, err = decrypt(key, val, c.decryptor)
+	if err != nil {
+		return "", err
+	}
+	return c.resolver.resolve(key, val, visiting)
+}
+
+func (c *compositePropertiesImpl) GetRaw(key string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return getFrom(c.ps, key)
+}
+
+func (c *compositePropertiesImpl) Retrive(key string, i interface{}) error {
+	return retrive(c, key, i)
+}
+
+func (c *compositePropertiesImpl) Set(key string, val interface{}) error {
+	c.lock.Lock()
+	if len(c.ps) == 0 {
+		c.ps = append(c.ps, NewProperties())
+	}
+	first := c.ps[0]
+	c.lock.Unlock()
+
+	// The Observable Properties will publish the events itself
+	if _, ok := first.(Observable); ok {
+		return first.Set(key, val)
+	}
+
+	// Expand the val to know which keys will been changed
+	p := propertiesImpl(make(map[string]string))
+	err := p.Set(key, val)
+	if err != nil {
+		return err
+	}
+
+	old := propertiesImpl(make(map[string]string, len(p)))
+	for k := range p {
+		if v, err := c.Get(k); err == nil {
+			old[k] = v
+		}
+	}
+
+	// The Properties which is not Observable may be not safe for concurrent use, so
+	// it's changed with the lock held, as it's read by Get/Keys/Snapshot with RLock.
+	c.lock.Lock()
+	err = first.Set(key, val)
+	c.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	c.listeners.publish(diffProperties(old, p))
+	return nil
+}
+
+func (c *compositePropertiesImpl) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := map[string]bool{}
+	for _, p := range c.ps {
+		for _, k := range p.Keys() {
+			keys[k] = true
+		}
+	}
+
+	ret := make([]string, 0, len(keys))
+	for k := range keys {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (c *compositePropertiesImpl) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, p := range c.ps {
+		if p.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unset remove the key from all the Properties, otherwise the value in the Properties with
+// lower precedence will been visible.
+func (c *compositePropertiesImpl) Unset(key string) error {
+	c.lock.RLock()
+	ps := append([]Properties{}, c.ps...)
+	c.lock.RUnlock()
+
+	// Remove the key from the lowest precedence, so the events of the Observable
+	// Properties is converted correctly by effectiveEvents.
+	found := false
+	for i := len(ps) - 1; i >= 0; i-- {
+		removed := propertiesImpl(make(map[string]string))
+		for _, k := range ps[i].Keys() {
+			if IsSubKey(k, key) {
+				removed[k], _ = ps[i].Get(k)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		found = true
+		if _, ok := ps[i].(Observable); ok {
+			err := ps[i].Unset(key)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		c.lock.Lock()
+		err := ps[i].Unset(key)
+		c.lock.Unlock()
+		if err != nil {
+			return err
+		}
+
+		events := []ChangeEvent{}
+		for _, event := range diffProperties(removed, propertiesImpl{}) {
+			if _, err := getFrom(ps[:i], event.Key); err == nil {
+				continue
+			}
+			events = append(events, event)
+		}
+		c.listeners.publish(events)
+	}
+
+	if !found {
+		return xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return nil
+}
+
+// Origin return the origin of value in the Properties with the highest precedence.
+func (c *compositePropertiesImpl) Origin(key string) (Origin, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, p := range c.ps {
+		if p.Has(key) {
+			return p.Origin(key)
+		}
+	}
+	return Origin{}, xerrors.WrapNotFound("property with key='%v' not found", key)
+}
+
+// Snapshot compose the snapshots of all the Properties, which share the values with them
+// until they're changed. The encrypted values are still decrypted and the placeholders are
+// still resolved in Get, the resolved dynamic values are shared with the snapshot.
+func (c *compositePropertiesImpl) Snapshot() Properties {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	s := newCompositeProperties()
+	for _, p := range c.ps {
+		s.ps = append(s.ps, p.Snapshot())
+	}
+	s.decryptor = c.decryptor
+	s.resolver.copyCache(c.resolver)
+	return newReadOnlyProperties(s)
+}
+
+func (c *compositePropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(c, prefix)
+}
@@ -0,0 +1,120 @@
+package property
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+func TestCompositeProperties(t *testing.T) {
+	g := NewWithT(t)
+
+	high := propertiesImpl(map[string]string{
+		"k1": "high",
+	})
+	low := propertiesImpl(map[string]string{
+		"k1": "low",
+		"k2": "2",
+	})
+	c := NewCompositeProperties(high, low)
+
+	v, err := c.Get("k1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("high"))
+
+	var i int
+	err = c.Retrive("k2", &i)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(i).To(Equal(2))
+
+	_, err = c.Get("k0")
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+
+	c.AddFirst(propertiesImpl(map[string]string{
+		"k2": "3",
+	}))
+	c.AddLast(propertiesImpl(map[string]string{
+		"k3": "last",
+	}))
+	err = c.Retrive("k2", &i)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(i).To(Equal(3))
+
+	v, err = c.Get("k3")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("last"))
+
+	err = c.Set("k1", "set")
+	g.Expect(err).ToNot(HaveOccurred())
+	v, err = c.Get("k1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("set"))
+	g.Expect(high["k1"]).To(Equal("high"))
+}
+
+func TestCompositeUnset(t *testing.T) {
+	g := NewWithT(t)
+
+	high := propertiesImpl(map[string]string{
+		"k1":   "high",
+		"k2.a": "a",
+	})
+	low := propertiesImpl(map[string]string{
+		"k1": "low",
+		"k3": "3",
+	})
+	c := NewCompositeProperties(high, low)
+	events := []ChangeEvent{}
+	c.AddChangeListener(func(es []ChangeEvent) {
+		events = append(events, es...)
+	})
+
+	g.Expect(c.Keys()).To(Equal([]string{"k1", "k2.a", "k3"}))
+	g.Expect(c.Has("k3")).To(BeTrue())
+	g.Expect(c.Sub("k2").Keys()).To(Equal([]string{"a"}))
+
+	err := c.Unset("k1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(c.Has("k1")).To(BeFalse())
+	g.Expect(events).To(Equal([]ChangeEvent{
+		{
+			Type:     ChangeTypeRemoved,
+			Key:      "k1",
+			OldValue: "high",
+		},
+	}))
+
+	err = c.Unset("k1")
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+}
+
+func TestCompositeConcurrentSet(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewCompositeProperties(NewProperties())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				g.Expect(c.Set(fmt.Sprintf("k%v.v%v", i, j), j)).ToNot(HaveOccurred())
+				g.Expect(c.Unset(fmt.Sprintf("k%v.v%v", i, j))).ToNot(HaveOccurred())
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = c.Get("k0.v0")
+				_ = c.Keys()
+				_ = c.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+	g.Expect(c.Keys()).To(BeEmpty())
+}
@@ -0,0 +1,97 @@
+package property
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// Converter convert the property value to the value of specified type.
+type Converter func(s string) (reflect.Value, error)
+
+// ConverterRegistry hold the converters for types, the converter is consulted before
+// the built-in conversions of Properties.Retrive.
+type ConverterRegistry interface {
+	// Register the converter fn for type T, the fn must be func(string) (T, error).
+	// It will overwrite the converter if T is already registered.
+	Register(fn interface{}) error
+
+	// Lookup return the converter for typ, it will return false if there is no such converter.
+	Lookup(typ reflect.Type) (Converter, bool)
+}
+
+// NewConverterRegistry return the ConverterRegistry impl
+func NewConverterRegistry() ConverterRegistry {
+	return &converterRegistryImpl{
+		converters: make(map[reflect.Type]Converter),
+	}
+}
+
+// DefaultConverterRegistry is the ConverterRegistry used by Properties.Retrive
+var DefaultConverterRegistry = NewConverterRegistry()
+
+// RegisterConverter register the converter fn with DefaultConverterRegistry, eg:
+//
+//	property.RegisterConverter(func(s string) (LogLevel, error) {
+//		return ParseLogLevel(s)
+//	})
+func RegisterConverter(fn interface{}) error {
+	return DefaultConverterRegistry.Register(fn)
+}
+
+var (
+	stringType = reflect.TypeOf("")
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type converterRegistryImpl struct {
+	converters map[reflect.Type]Converter
+	lock       sync.RWMutex
+}
+
+func (r *converterRegistryImpl) Register(fn interface{}) error {
+	// NOTE: the fn may be nil, which has the zero Value and nil Type
+	fv, ft := reflect.ValueOf(fn), reflect.TypeOf(fn)
+	if !fv.IsValid() || ft.Kind() != reflect.Func || fv.IsNil() ||
+		ft.NumIn() != 1 || ft.In(0) != stringType ||
+		ft.NumOut() != 2 || ft.Out(1) != errorType {
+		return xerrors.Errorf("Cannot register converter '%T', it must be func(string) (T, error)", fn)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.converters[ft.Out(0)] = func(s string) (reflect.Value, error) {
+		out := fv.Call([]reflect.Value{reflect.ValueOf(s)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return reflect.Value{}, err
+		}
+		return out[0], nil
+	}
+	return nil
+}
+
+func (r *converterRegistryImpl) Lookup(typ reflect.Type) (Converter, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	c, ok := r.converters[typ]
+	return c, ok
+}
+
+// retriveConverted convert the vstr with the registered converter, it will return false if
+// there is no converter for the type of v.
+func retriveConverted(key string, vstr string, v reflect.Value) (bool, error) {
+	c, ok := DefaultConverterRegistry.Lookup(v.Type())
+	if !ok {
+		return false, nil
+	}
+
+	cv, err := c(vstr)
+	if err != nil {
+		return true, xerrors.Wrapf(err, "Cannot convert value '%v' to '%v' with key '%v'", vstr, v.Type(), key)
+	}
+	v.Set(cv)
+	return true, nil
+}
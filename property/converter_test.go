@@ -0,0 +1,129 @@
+package property
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+type testPort int
+
+type testEndpoint struct {
+	Host string
+}
+
+func TestConverterRegistry(t *testing.T) {
+	type testCase struct {
+		desp string
+		fn   interface{}
+		err  string
+	}
+	testCases := []testCase{
+		{
+			desp: "normal converter",
+			fn: func(s string) (testPort, error) {
+				return 0, nil
+			},
+			err: "",
+		},
+		{
+			desp: "not func",
+			fn:   "converter",
+			err:  "Cannot register converter 'string', it must be func\\(string\\) \\(T, error\\)",
+		},
+		{
+			desp: "invalid argument",
+			fn: func(i int) (testPort, error) {
+				return 0, nil
+			},
+			err: "Cannot register converter 'func\\(int\\) \\(property.testPort, error\\)'",
+		},
+		{
+			desp: "invalid return",
+			fn: func(s string) testPort {
+				return 0
+			},
+			err: "Cannot register converter 'func\\(string\\) property.testPort'",
+		},
+		{
+			desp: "nil",
+			fn:   nil,
+			err:  "Cannot register converter '<nil>', it must be func\\(string\\) \\(T, error\\)",
+		},
+		{
+			desp: "nil func",
+			fn:   (func(s string) (testPort, error))(nil),
+			err:  "Cannot register converter",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			r := NewConverterRegistry()
+			err := r.Register(tc.fn)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			c, ok := r.Lookup(reflect.TypeOf(testPort(0)))
+			g.Expect(ok).To(BeTrue())
+			v, err := c("1")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(v.Interface()).To(Equal(testPort(0)))
+		})
+	}
+}
+
+func TestRetriveWithConverter(t *testing.T) {
+	g := NewWithT(t)
+
+	err := RegisterConverter(func(s string) (testPort, error) {
+		switch s {
+		case "http":
+			return 80, nil
+		case "https":
+			return 443, nil
+		}
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, xerrors.Errorf("unknown port '%v'", s)
+		}
+		return testPort(i), nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	err = RegisterConverter(func(s string) (*testEndpoint, error) {
+		return &testEndpoint{
+			Host: "converted:" + s,
+		}, nil
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := propertiesImpl(map[string]string{
+		"port":     "https",
+		"invalid":  "ftp",
+		"endpoint": "localhost",
+	})
+
+	var port testPort
+	err = p.Retrive("port", &port)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(port).To(Equal(testPort(443)))
+
+	err = p.Retrive("invalid", &port)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot convert value 'ftp' to 'property.testPort' with key 'invalid': unknown port 'ftp'"))
+
+	var endpoint *testEndpoint
+	err = p.Retrive("endpoint", &endpoint)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(endpoint).To(Equal(&testEndpoint{
+		Host: "converted:localhost",
+	}))
+}
@@ -0,0 +1,39 @@
+package property
+
+import (
+	"os"
+	"strings"
+)
+
+// FromEnv return the Properties with the env vars which have the prefix, the key is
+// the name of env var without prefix in lower case, and the "_" is replaced by ".",
+// eg: the env var NUWA_DB_HOST with prefix NUWA is the property db.host.
+// All the env vars are included if the prefix is empty.
+func FromEnv(prefix string) Properties {
+	return fromEnviron(os.Environ(), prefix)
+}
+
+func fromEnviron(environ []string, prefix string) Properties {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix = prefix + "_"
+	}
+
+	p := newTrackedProperties()
+	for _, env := range environ {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], prefix) {
+			continue
+		}
+
+		key := strings.ToLower(strings.ReplaceAll(kv[0][len(prefix):], "_", "."))
+		if key == "" {
+			continue
+		}
+		p.propertiesImpl[key] = kv[1]
+		p.origins[key] = Origin{
+			Source: OriginSourceEnv,
+			Name:   kv[0],
+		}
+	}
+	return p
+}
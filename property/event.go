@@ -0,0 +1,133 @@
+package property
+
+import (
+	"sort"
+	"sync"
+)
+
+// ChangeType is the type of property change
+type ChangeType = string
+
+const (
+	// ChangeTypeAdded identifier the property is added
+	ChangeTypeAdded ChangeType = "added"
+
+	// ChangeTypeModified identifier the property value is modified
+	ChangeTypeModified ChangeType = "modified"
+
+	// ChangeTypeRemoved identifier the property is removed
+	ChangeTypeRemoved ChangeType = "removed"
+)
+
+// ChangeEvent is the event of property value change.
+// The OldValue is empty if the property is added, and the NewValue is empty if
+// the property is removed.
+type ChangeEvent struct {
+	Type     ChangeType
+	Key      string
+	OldValue string
+	NewValue string
+}
+
+// ChangeListener will been called with the change events after the property values changed.
+// NOTE: the listener should not block, it's called synchronously with the change.
+type ChangeListener func(events []ChangeEvent)
+
+// Observable is the interface which publish the property change events.
+type Observable interface {
+	// AddChangeListener register the listener for change events, and return the function
+	// which remove the listener.
+	AddChangeListener(l ChangeListener) (remove func())
+}
+
+// listeners is the list of ChangeListener which is safe for concurrent use.
+type listeners struct {
+	ls     []listenerEntry
+	nextID int
+	lock   sync.RWMutex
+}
+
+// listenerEntry is the registered listener, the id identify it for removing
+type listenerEntry struct {
+	id       int
+	listener ChangeListener
+}
+
+func (l *listeners) add(listener ChangeListener) func() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	id := l.nextID
+	l.nextID++
+	l.ls = append(l.ls, listenerEntry{
+		id:       id,
+		listener: listener,
+	})
+	return func() {
+		l.remove(id)
+	}
+}
+
+func (l *listeners) remove(id int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for i, entry := range l.ls {
+		if entry.id == id {
+			l.ls = append(l.ls[:i:i], l.ls[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *listeners) publish(events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	l.lock.RLock()
+	ls := append([]listenerEntry{}, l.ls...)
+	l.lock.RUnlock()
+	for _, entry := range ls {
+		entry.listener(events)
+	}
+}
+
+// diffProperties return the change events from old to new, the events are sorted by key.
+func diffProperties(old propertiesImpl, new propertiesImpl) []ChangeEvent {
+	events := []ChangeEvent{}
+	for k, v := range old {
+		nv, ok := new[k]
+		if !ok {
+			events = append(events, ChangeEvent{
+				Type:     ChangeTypeRemoved,
+				Key:      k,
+				OldValue: v,
+			})
+			continue
+		}
+
+		if nv != v {
+			events = append(events, ChangeEvent{
+				Type:     ChangeTypeModified,
+				Key:      k,
+				OldValue: v,
+				NewValue: nv,
+			})
+		}
+	}
+	for k, v := range new {
+		if _, ok := old[k]; !ok {
+			events = append(events, ChangeEvent{
+				Type:     ChangeTypeAdded,
+				Key:      k,
+				NewValue: v,
+			})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
@@ -0,0 +1,188 @@
+package property
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// Export return the nested structure of p, which is the inverse of Properties.Set, eg:
+// the db.host is exported as {"db": {"host": ...}}, and the servers[0] and servers[1]
+// are exported as {"servers": [...]}. The missing elements of array are nil, and the index
+// greater than MaxArrayIndex is rejected.
+// The values are exported as string as Entries, the secret values are masked and the
+// placeholders are not resolved.
+func Export(p Properties) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	for _, entry := range Entries(p) {
+		path, err := parseKeyPath(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = exportValue(root, path, entry.Value)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot export property with key '%v'", entry.Key)
+		}
+	}
+	return exportFinalize(root).(map[string]interface{}), nil
+}
+
+// ToBytes return the exported structure of p encoded in the format specified by
+// ext, which is one of .yaml/.yml/.json, it's the inverse of FromBytes.
+func ToBytes(p Properties, ext string) ([]byte, error) {
+	vals, err := Export(p)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		data, err := yaml.Marshal(vals)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot encode yaml")
+		}
+		return data, nil
+	case ".json":
+		data, err := json.MarshalIndent(vals, "", "  ")
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot encode json")
+		}
+		return append(data, '\n'), nil
+	}
+	return nil, xerrors.Errorf("Cannot encode property with unsupported format '%v'", ext)
+}
+
+// MaxArrayIndex is the max index of array in key, the larger index is rejected because the
+// array is allocated with the size of the max index, eg: servers[9000000000].
+const MaxArrayIndex = 65535
+
+// keySegment is the segment of key, which is either the name or the index of array
+type keySegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// parseKeyPath split the key to segments, eg: servers[0].host to [servers, 0, host]
+func parseKeyPath(key string) ([]keySegment, error) {
+	path := []keySegment{}
+	rest := key
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			idx := strings.Index(rest, "]")
+			if idx < 0 {
+				return nil, xerrors.Errorf("Cannot parse key '%v', the '[' is not closed", key)
+			}
+			i, err := strconv.Atoi(rest[1:idx])
+			if err != nil || i < 0 {
+				return nil, xerrors.Errorf("Cannot parse key '%v', the index '%v' is invalid", key, rest[1:idx])
+			}
+			if i > MaxArrayIndex {
+				return nil, xerrors.Errorf("Cannot parse key '%v', the index %v is greater than %v", key, i, MaxArrayIndex)
+			}
+			path = append(path, keySegment{
+				index:   i,
+				isIndex: true,
+			})
+			rest = rest[idx+1:]
+		default:
+			idx := strings.IndexAny(rest, ".[")
+			if idx < 0 {
+				idx = len(rest)
+			}
+			path = append(path, keySegment{
+				name: rest[:idx],
+			})
+			rest = rest[idx:]
+		}
+	}
+
+	if len(path) == 0 {
+		return nil, xerrors.Errorf("Cannot parse key '%v', it's empty", key)
+	}
+	return path, nil
+}
+
+// exportArray is the array under construction, it's converted to slice by exportFinalize
+type exportArray map[int]interface{}
+
+// exportValue set the val in node with path, the node is map[string]interface{} or exportArray
+func exportValue(node interface{}, path []keySegment, val string) error {
+	seg := path[0]
+
+	var child interface{}
+	var exists bool
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if seg.isIndex {
+			return xerrors.Errorf("Cannot set index %v of map", seg.index)
+		}
+		child, exists = n[seg.name]
+	case exportArray:
+		if !seg.isIndex {
+			return xerrors.Errorf("Cannot set field '%v' of array", seg.name)
+		}
+		child, exists = n[seg.index]
+	}
+
+	if len(path) == 1 {
+		if exists {
+			return xerrors.Errorf("Cannot set value, it conflicts with the sub keys")
+		}
+		exportPut(node, seg, val)
+		return nil
+	}
+
+	if !exists {
+		child = map[string]interface{}{}
+		if path[1].isIndex {
+			child = exportArray{}
+		}
+		exportPut(node, seg, child)
+	}
+	if _, ok := child.(string); ok {
+		return xerrors.Errorf("Cannot set sub keys, it conflicts with the value")
+	}
+	return exportValue(child, path[1:], val)
+}
+
+func exportPut(node interface{}, seg keySegment, val interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[seg.name] = val
+	case exportArray:
+		n[seg.index] = val
+	}
+}
+
+// exportFinalize convert the exportArray in node to slice
+func exportFinalize(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			n[k] = exportFinalize(v)
+		}
+		return n
+	case exportArray:
+		size := 0
+		for i := range n {
+			if i+1 > size {
+				size = i + 1
+			}
+		}
+		ret := make([]interface{}, size)
+		for i, v := range n {
+			ret[i] = exportFinalize(v)
+		}
+		return ret
+	}
+	return node
+}
@@ -0,0 +1,133 @@
+package property
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestExport(t *testing.T) {
+	type testCase struct {
+		desp   string
+		values map[string]string
+		err    string
+		expect map[string]interface{}
+	}
+	testCases := []testCase{
+		{
+			desp: "nested map and array",
+			values: map[string]string{
+				"db.host":            "localhost",
+				"db.password":        "abc",
+				"servers[0].host":    "a",
+				"servers[1].host":    "b",
+				"servers[1].tags[1]": "x",
+				"matrix[0][0]":       "1",
+				"name":               "api",
+			},
+			err: "",
+			expect: map[string]interface{}{
+				"db": map[string]interface{}{
+					"host":     "localhost",
+					"password": MaskedValue,
+				},
+				"servers": []interface{}{
+					map[string]interface{}{
+						"host": "a",
+					},
+					map[string]interface{}{
+						"host": "b",
+						"tags": []interface{}{nil, "x"},
+					},
+				},
+				"matrix": []interface{}{
+					[]interface{}{"1"},
+				},
+				"name": "api",
+			},
+		},
+		{
+			desp: "value conflicts with sub keys",
+			values: map[string]string{
+				"db":      "x",
+				"db.host": "localhost",
+			},
+			err:    "Cannot export property with key 'db.host'",
+			expect: nil,
+		},
+		{
+			desp: "map conflicts with array",
+			values: map[string]string{
+				"db.host": "localhost",
+				"db[0]":   "x",
+			},
+			err:    "Cannot export property with key 'db\\[0\\]': Cannot set index 0 of map",
+			expect: nil,
+		},
+		{
+			desp: "invalid index",
+			values: map[string]string{
+				"db[x]": "x",
+			},
+			err:    "Cannot parse key 'db\\[x\\]', the index 'x' is invalid",
+			expect: nil,
+		},
+		{
+			desp: "index too large",
+			values: map[string]string{
+				"db[9000000000000000000]": "x",
+			},
+			err:    "Cannot parse key 'db\\[9000000000000000000\\]', the index 9000000000000000000 is greater than 65535",
+			expect: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, err := Export(propertiesImpl(tc.values))
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestToBytes(t *testing.T) {
+	g := NewWithT(t)
+
+	p := propertiesImpl(map[string]string{
+		"db.host":    "localhost",
+		"db.port":    "3306",
+		"servers[0]": "a",
+		"servers[1]": "b",
+	})
+
+	data, err := ToBytes(p, ".json")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(string(data)).To(Equal(`{
+  "db": {
+    "host": "localhost",
+    "port": "3306"
+  },
+  "servers": [
+    "a",
+    "b"
+  ]
+}
+`))
+
+	data, err = ToBytes(p, ".yaml")
+	g.Expect(err).ToNot(HaveOccurred())
+	actual, err := FromBytes(data, ".yaml")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(actual.(*trackedPropertiesImpl).propertiesImpl).To(Equal(p))
+
+	_, err = ToBytes(p, ".properties")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot encode property with unsupported format '.properties'"))
+}
@@ -0,0 +1,197 @@
+package property
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lsytj0413/nuwa/logger"
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// FromFile return the Properties loaded from the file, the format is detected by
+// the extension of path:
+//  1. .yaml/.yml: the file is decoded as YAML
+//  2. .json: the file is decoded as JSON
+//
+// The nested maps and arrays are flattened as Properties.Set does.
+func FromFile(path string) (Properties, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot read property file '%v'", path)
+	}
+
+	p, err := fromBytes(data, filepath.Ext(path), path)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot load property file '%v'", path)
+	}
+	logger.Default().Debug("Property file loaded", "path", path, "keys", len(p.Keys()))
+	return p, nil
+}
+
+// FromBytes return the Properties decoded from data, the format is specified by
+// the ext, which is one of .yaml/.yml/.json
+func FromBytes(data []byte, ext string) (Properties, error) {
+	return fromBytes(data, ext, "")
+}
+
+// fromBytes decode the data, and record the path and line in the file as origin of values.
+func fromBytes(data []byte, ext string, path string) (Properties, error) {
+	vals := map[string]interface{}{}
+	var lines map[string]int
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		// NOTE: the data is decoded to the node tree once, which has the lines of keys
+		var root yaml.Node
+		err := yaml.Unmarshal(data, &root)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot decode yaml")
+		}
+		// The empty document has no content, it's decoded as empty map
+		if len(root.Content) != 0 {
+			err = root.Decode(&vals)
+			if err != nil {
+				return nil, xerrors.Wrapf(err, "Cannot decode yaml")
+			}
+		}
+		lines = yamlLines(&root)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err := decoder.Decode(&vals)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "Cannot decode json")
+		}
+		lines = jsonLines(data)
+	default:
+		return nil, xerrors.Errorf("Cannot decode property with unsupported format '%v'", ext)
+	}
+
+	p := newTrackedProperties()
+	for k, v := range vals {
+		err := p.Set(k, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for k := range p.origins {
+		p.origins[k] = Origin{
+			Source: OriginSourceFile,
+			Name:   path,
+			Line:   lineOf(lines, k),
+		}
+	}
+	return p, nil
+}
+
+// ProfileFileName return the profile-specific file name for path,
+// eg: the profile file of application.yaml for profile dev is application-dev.yaml
+func ProfileFileName(path string, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + profile + ext
+}
+
+// lineOf return the line of key, or the line of it's nearest parent if the key is
+// not recorded, eg: the sub keys of alias *ref in YAML.
+func lineOf(lines map[string]int, key string) int {
+	for key != "" {
+		if line, ok := lines[key]; ok {
+			return line
+		}
+
+		idx := strings.LastIndexAny(key, ".[")
+		if idx < 0 {
+			break
+		}
+		key = key[:idx]
+	}
+	return 0
+}
+
+// yamlLines return the lines of keys in the YAML node tree, the elements of flow style are
+// located at the lines of themselves too, eg: the x of tags: [x, y].
+func yamlLines(root *yaml.Node) map[string]int {
+	lines := map[string]int{}
+	var walk func(path string, n *yaml.Node)
+	walk = func(path string, n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(path, c)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := subKey(path, n.Content[i].Value)
+				lines[key] = n.Content[i].Line
+				walk(key, n.Content[i+1])
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				key := fmt.Sprintf("%v[%v]", path, i)
+				lines[key] = c.Line
+				walk(key, c)
+			}
+		}
+	}
+	walk("", root)
+	return lines
+}
+
+// jsonLines return the lines of keys in the JSON data
+func jsonLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	lineAt := func() int {
+		return bytes.Count(data[:decoder.InputOffset()], []byte("\n")) + 1
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := lines[path]; !ok && path != "" {
+			lines[path] = lineAt()
+		}
+
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		switch delim {
+		case '{':
+			for decoder.More() {
+				tok, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key := subKey(path, fmt.Sprintf("%v", tok))
+				lines[key] = lineAt()
+				err = walk(key)
+				if err != nil {
+					return err
+				}
+			}
+		case '[':
+			for i := 0; decoder.More(); i++ {
+				err := walk(fmt.Sprintf("%v[%v]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Consume the end of object or array
+		_, err = decoder.Token()
+		return err
+	}
+
+	_ = walk("")
+	return lines
+}
@@ -0,0 +1,111 @@
+package property
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFromBytes(t *testing.T) {
+	type testCase struct {
+		desp   string
+		data   string
+		ext    string
+		err    string
+		expect Properties
+	}
+	testCases := []testCase{
+		{
+			desp: "normal yaml",
+			data: `
+db:
+  host: localhost
+  port: 3306
+servers:
+  - a
+  - b
+`,
+			ext: ".yaml",
+			err: "",
+			expect: propertiesImpl(map[string]string{
+				"db.host":    "localhost",
+				"db.port":    "3306",
+				"servers[0]": "a",
+				"servers[1]": "b",
+			}),
+		},
+		{
+			desp: "normal json",
+			data: `{"db": {"host": "localhost", "port": 3306, "ratio": 0.5}, "servers": ["a"]}`,
+			ext:  ".json",
+			err:  "",
+			expect: propertiesImpl(map[string]string{
+				"db.host":    "localhost",
+				"db.port":    "3306",
+				"db.ratio":   "0.5",
+				"servers[0]": "a",
+			}),
+		},
+		{
+			desp:   "invalid yaml",
+			data:   "db: [",
+			ext:    ".yml",
+			err:    "Cannot decode yaml",
+			expect: nil,
+		},
+		{
+			desp:   "unsupported format",
+			data:   "db.host=localhost",
+			ext:    ".properties",
+			err:    "Cannot decode property with unsupported format '.properties'",
+			expect: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, err := FromBytes([]byte(tc.data), tc.ext)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual.(*trackedPropertiesImpl).propertiesImpl).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "application.yaml")
+	err = ioutil.WriteFile(path, []byte("k1: v1"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p, err := FromFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p.(*trackedPropertiesImpl).propertiesImpl).To(Equal(propertiesImpl(map[string]string{
+		"k1": "v1",
+	})))
+
+	_, err = FromFile(filepath.Join(dir, "missing.yaml"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(MatchRegexp("Cannot read property file"))
+}
+
+func TestProfileFileName(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ProfileFileName("conf/application.yaml", "dev")).To(Equal("conf/application-dev.yaml"))
+	g.Expect(ProfileFileName("application.json", "prod")).To(Equal("application-prod.json"))
+	g.Expect(ProfileFileName("application", "prod")).To(Equal("application-prod"))
+}
@@ -0,0 +1,258 @@
+package property
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// OriginSource is the kind of source which the property value comes from
+type OriginSource = string
+
+const (
+	// OriginSourceUnknown identifier the origin of value is not tracked
+	OriginSourceUnknown OriginSource = "unknown"
+
+	// OriginSourceSet identifier the value is set by Properties.Set
+	OriginSourceSet OriginSource = "set"
+
+	// OriginSourceArgs identifier the value is parsed from the command-line args
+	OriginSourceArgs OriginSource = "args"
+
+	// OriginSourceFlag identifier the value is parsed from the flag
+	OriginSourceFlag OriginSource = "flag"
+
+	// OriginSourceFile identifier the value is loaded from file
+	OriginSourceFile OriginSource = "file"
+
+	// OriginSourceEnv identifier the value is loaded from the env var
+	OriginSourceEnv OriginSource = "env"
+)
+
+// Origin is where the property value comes from.
+type Origin struct {
+	// Source is the kind of source, eg: file, env, args
+	Source OriginSource
+
+	// Name identifier the source, which is the file path, the env var name, the arg,
+	// the flag or the call site of Set
+	Name string
+
+	// Line is the line of value in file, it's 0 if unknown
+	Line int
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		o.Source = OriginSourceUnknown
+	}
+	if o.Name == "" {
+		return o.Source
+	}
+	if o.Line > 0 {
+		return fmt.Sprintf("%v %v:%v", o.Source, o.Name, o.Line)
+	}
+	return fmt.Sprintf("%v %v", o.Source, o.Name)
+}
+
+// callerOrigin return the origin of Set, which is the first caller outside of this package
+func callerOrigin() Origin {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		inPackage := strings.HasPrefix(frame.Function, packagePath+".") && !strings.HasSuffix(frame.File, "_test.go")
+		if !inPackage && frame.File != "<autogenerated>" {
+			return Origin{
+				Source: OriginSourceSet,
+				Name:   fmt.Sprintf("%v:%v", frame.File, frame.Line),
+			}
+		}
+		if !more {
+			return Origin{
+				Source: OriginSourceSet,
+			}
+		}
+	}
+}
+
+// packagePath is the import path of this package, eg: github.com/lsytj0413/nuwa/property
+var packagePath = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	idx := strings.LastIndex(name, "/")
+	return name[:idx+strings.Index(name[idx:], ".")]
+}()
+
+// newTrackedProperties return the Properties which track the origin of values
+func newTrackedProperties() *trackedPropertiesImpl {
+	return &trackedPropertiesImpl{
+		propertiesImpl: make(map[string]string),
+		origins:        make(map[string]Origin),
+	}
+}
+
+// trackedPropertiesImpl is the propertiesImpl which record the origin of every value.
+type trackedPropertiesImpl struct {
+	propertiesImpl
+	origins map[string]Origin
+	// shared is 1 if the values are shared with the snapshot, they must been copied
+	// before the first write
+	shared int32
+}
+
+func (t *trackedPropertiesImpl) Retrive(key string, i interface{}) error {
+	return retrive(t, key, i)
+}
+
+func (t *trackedPropertiesImpl) Set(key string, val interface{}) error {
+	return t.set(key, val, callerOrigin())
+}
+
+// set store the val for key, and record the origin for the expanded keys
+func (t *trackedPropertiesImpl) set(key string, val interface{}, origin Origin) error {
+	p := propertiesImpl(make(map[string]string))
+	err := p.Set(key, val)
+	if err != nil {
+		return err
+	}
+
+	t.own()
+	for k, v := range p {
+		t.propertiesImpl[k] = v
+		t.origins[k] = origin
+	}
+	return nil
+}
+
+func (t *trackedPropertiesImpl) Unset(key string) error {
+	t.own()
+	err := t.propertiesImpl.Unset(key)
+	if err != nil {
+		return err
+	}
+
+	for k := range t.origins {
+		if IsSubKey(k, key) {
+			delete(t.origins, k)
+		}
+	}
+	return nil
+}
+
+func (t *trackedPropertiesImpl) Origin(key string) (Origin, error) {
+	if !t.Has(key) {
+		return Origin{}, xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return t.origins[key], nil
+}
+
+func (t *trackedPropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(t, prefix)
+}
+
+// Snapshot share the values with t, which are copied before the first write of t
+func (t *trackedPropertiesImpl) Snapshot() Properties {
+	return newReadOnlyProperties(t.share())
+}
+
+// share return the copy of t which share the values with t, both of them will copy the
+// values before the first write
+func (t *trackedPropertiesImpl) share() *trackedPropertiesImpl {
+	atomic.StoreInt32(&t.shared, 1)
+	return &trackedPropertiesImpl{
+		propertiesImpl: t.propertiesImpl,
+		origins:        t.origins,
+		shared:         1,
+	}
+}
+
+// own copy the values if they are shared, it must been called before the write
+func (t *trackedPropertiesImpl) own() {
+	if atomic.LoadInt32(&t.shared) == 0 {
+		return
+	}
+
+	c := t.clone()
+	t.propertiesImpl = c.propertiesImpl
+	t.origins = c.origins
+	atomic.StoreInt32(&t.shared, 0)
+}
+
+// clone return the copy of t
+func (t *trackedPropertiesImpl) clone() *trackedPropertiesImpl {
+	c := &trackedPropertiesImpl{
+		propertiesImpl: make(map[string]string, len(t.propertiesImpl)),
+		origins:        make(map[string]Origin, len(t.origins)),
+	}
+	for k, v := range t.propertiesImpl {
+		c.propertiesImpl[k] = v
+	}
+	for k, v := range t.origins {
+		c.origins[k] = v
+	}
+	return c
+}
+
+// PropertyEntry is the effective value of property and where it comes from
+type PropertyEntry struct {
+	Key    string
+	Value  string
+	Origin Origin
+}
+
+// Entries return the effective entries of p in ascending order of key, the secret values
+// are masked. The value with placeholders is not resolved, so the secret interpolated by
+// placeholder will not leak, eg: pg://user:${db.password}@localhost.
+func Entries(p Properties) []PropertyEntry {
+	keys := p.Keys()
+	ret := make([]PropertyEntry, 0, len(keys))
+	for _, key := range keys {
+		origin, _ := p.Origin(key)
+		ret = append(ret, PropertyEntry{
+			Key:    key,
+			Value:  entryValue(p, key),
+			Origin: origin,
+		})
+	}
+	return ret
+}
+
+// entryValue return the masked value of key if it's secret, or the raw value if it
+// contains placeholders, otherwise the effective value
+func entryValue(p Properties, key string) string {
+	if isSecret(p, key) {
+		return MaskedValue
+	}
+
+	val, err := GetRaw(p, key)
+	if err != nil {
+		return ""
+	}
+	if strings.Contains(val, "${") {
+		return val
+	}
+
+	val, err = p.Get(key)
+	if err != nil {
+		return ""
+	}
+	return val
+}
+
+// Dump write the effective entries of p to w, one entry per line in form of
+// "key=value # origin", eg: "db.host=localhost # file conf/application.yaml:3".
+func Dump(w io.Writer, p Properties) error {
+	for _, entry := range Entries(p) {
+		_, err := fmt.Fprintf(w, "%v=%v # %v\n", entry.Key, entry.Value, entry.Origin)
+		if err != nil {
+			return xerrors.Wrapf(err, "Cannot dump property with key '%v'", entry.Key)
+		}
+	}
+	return nil
+}
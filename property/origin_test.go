@@ -0,0 +1,242 @@
+package property
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+func TestOrigin(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "application.yaml")
+	err = ioutil.WriteFile(path, []byte(`# comment
+db:
+  host: localhost
+  port: 3306
+  password: ENC(abc)
+servers:
+  - host: a
+    port: 80
+  - host: b
+tags: [x, y]
+`), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	file, err := FromFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	args, err := FromArgs([]string{"--db.port=3307"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "", "")
+	err = fs.Parse([]string{"-name=api"})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	env := fromEnviron([]string{"NUWA_DB_USER=root", "HOME=/root"}, "NUWA")
+	c := NewCompositeProperties(NewProperties(), args, FromFlagSet(fs), env, file)
+	err = c.Set("db.name", "test")
+	_, testFile, line, _ := runtime.Caller(0)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	type testCase struct {
+		key    string
+		expect Origin
+	}
+	testCases := []testCase{
+		{
+			key: "db.host",
+			expect: Origin{
+				Source: OriginSourceFile,
+				Name:   path,
+				Line:   3,
+			},
+		},
+		{
+			key: "servers[1].host",
+			expect: Origin{
+				Source: OriginSourceFile,
+				Name:   path,
+				Line:   9,
+			},
+		},
+		{
+			key: "servers[0].port",
+			expect: Origin{
+				Source: OriginSourceFile,
+				Name:   path,
+				Line:   8,
+			},
+		},
+		{
+			key: "tags[1]",
+			expect: Origin{
+				Source: OriginSourceFile,
+				Name:   path,
+				Line:   10,
+			},
+		},
+		{
+			key: "db.port",
+			expect: Origin{
+				Source: OriginSourceArgs,
+				Name:   "--db.port=3307",
+			},
+		},
+		{
+			key: "name",
+			expect: Origin{
+				Source: OriginSourceFlag,
+				Name:   "-name",
+			},
+		},
+		{
+			key: "db.user",
+			expect: Origin{
+				Source: OriginSourceEnv,
+				Name:   "NUWA_DB_USER",
+			},
+		},
+		{
+			key: "db.name",
+			expect: Origin{
+				Source: OriginSourceSet,
+				Name:   testFile + ":" + strconv.Itoa(line-1),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		origin, err := c.Origin(tc.key)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(origin).To(Equal(tc.expect), tc.key)
+	}
+
+	origin, err := c.Sub("db").Origin("host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(origin.String()).To(Equal("file " + path + ":3"))
+
+	_, err = c.Origin("missing")
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+	g.Expect(c.Has("home")).To(BeFalse())
+
+	buf := &bytes.Buffer{}
+	err = Dump(buf, c.Sub("db"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(buf.String()).To(Equal("host=localhost # file " + path + ":3\n" +
+		"name=test # set " + testFile + ":" + strconv.Itoa(line-1) + "\n" +
+		"password=****** # file " + path + ":5\n" +
+		"port=3307 # args --db.port=3307\n" +
+		"user=root # env NUWA_DB_USER\n"))
+}
+
+func TestEntries(t *testing.T) {
+	g := NewWithT(t)
+
+	values := func(entries []PropertyEntry) map[string]string {
+		ret := map[string]string{}
+		for _, entry := range entries {
+			ret[entry.Key] = entry.Value
+		}
+		return ret
+	}
+
+	c := NewCompositeProperties(propertiesImpl(map[string]string{
+		"db.host":     "localhost",
+		"db.password": "p",
+		"db.token":    "ENC(abc)",
+		"db.key":      "ENC(abc)",
+		"db.url":      "pg://u:${db.password}@${db.host}",
+	}))
+	g.Expect(values(Entries(c))).To(Equal(map[string]string{
+		"db.host":     "localhost",
+		"db.password": MaskedValue,
+		"db.token":    MaskedValue,
+		"db.key":      MaskedValue,
+		"db.url":      "pg://u:${db.password}@${db.host}",
+	}))
+	g.Expect(values(Entries(c.Sub("db")))).To(HaveKeyWithValue("key", MaskedValue))
+
+	// The encrypted key of other Properties is not secret
+	other := NewCompositeProperties(propertiesImpl(map[string]string{
+		"db.key": "plain",
+	}))
+	g.Expect(values(Entries(other))).To(Equal(map[string]string{
+		"db.key": "plain",
+	}))
+}
+
+func TestYAMLLines(t *testing.T) {
+	g := NewWithT(t)
+
+	var root yaml.Node
+	err := yaml.Unmarshal([]byte(`# comment
+db:
+  host: localhost
+  "port": 3306
+servers:
+  - host: a
+  -
+    b
+base: &base
+  name: x
+copy: *base
+tags: [x,
+  y]
+`), &root)
+	g.Expect(err).ToNot(HaveOccurred())
+	lines := yamlLines(&root)
+	g.Expect(lines).To(Equal(map[string]int{
+		"db":              2,
+		"db.host":         3,
+		"db.port":         4,
+		"servers":         5,
+		"servers[0]":      6,
+		"servers[0].host": 6,
+		"servers[1]":      8,
+		"base":            9,
+		"base.name":       10,
+		"copy":            11,
+		"tags":            12,
+		"tags[0]":         12,
+		"tags[1]":         13,
+	}))
+	g.Expect(lineOf(lines, "copy.name")).To(Equal(11))
+}
+
+func TestJSONLines(t *testing.T) {
+	g := NewWithT(t)
+
+	lines := jsonLines([]byte(`{
+  "db": {
+    "host": "localhost",
+    "port": 3306
+  },
+  "servers": [
+    {"host": "a"},
+    "b"
+  ]
+}`))
+	g.Expect(lines).To(Equal(map[string]int{
+		"db":              2,
+		"db.host":         3,
+		"db.port":         4,
+		"servers":         6,
+		"servers[0]":      7,
+		"servers[0].host": 7,
+		"servers[1]":      8,
+	}))
+}
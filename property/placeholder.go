@@ -0,0 +1,339 @@
+package property
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// PlaceholderSource resolve the value of dynamic placeholder, the expr is the placeholder
+// without the prefix of source, eg: the expr of ${env:HOME} is HOME.
+type PlaceholderSource func(expr string) (string, error)
+
+// PlaceholderRegistry hold the dynamic sources for placeholders. The placeholder ${xxx} in
+// the values of CompositeProperties is resolved by the source whose prefix matches xxx,
+// otherwise it's resolved as the value of key xxx.
+type PlaceholderRegistry interface {
+	// Register the source for placeholders with prefix, eg: "env:" or "random.".
+	// It will overwrite the source if prefix is already registered.
+	Register(prefix string, source PlaceholderSource)
+
+	// Lookup return the source with the longest prefix of placeholder, and the expr
+	// without prefix. It will return false if there is no such source.
+	Lookup(placeholder string) (PlaceholderSource, string, bool)
+}
+
+// NewPlaceholderRegistry return the PlaceholderRegistry impl without any source
+func NewPlaceholderRegistry() PlaceholderRegistry {
+	return &placeholderRegistryImpl{
+		sources: make(map[string]PlaceholderSource),
+	}
+}
+
+// DefaultPlaceholderRegistry is the PlaceholderRegistry used by CompositeProperties, the
+// built-in sources are:
+//  1. random.: ${random.int}, ${random.int(max)}, ${random.int(min,max)}, ${random.long},
+//     ${random.uuid} and ${random.value}, the min is inclusive and the max is exclusive
+//  2. env:: ${env:HOME} or ${env:HOME:/root} with default value
+//  3. file:: ${file:/run/secrets/db}, the trailing newline of content is trimmed
+var DefaultPlaceholderRegistry = func() PlaceholderRegistry {
+	r := NewPlaceholderRegistry()
+	r.Register(randomPrefix, randomSource)
+	r.Register("env:", envSource)
+	r.Register("file:", fileSource)
+	return r
+}()
+
+// RegisterPlaceholderSource register the source with DefaultPlaceholderRegistry, eg:
+//
+//	property.RegisterPlaceholderSource("vault:", func(expr string) (string, error) {
+//		return vault.Read(expr)
+//	})
+func RegisterPlaceholderSource(prefix string, source PlaceholderSource) {
+	DefaultPlaceholderRegistry.Register(prefix, source)
+}
+
+type placeholderRegistryImpl struct {
+	sources map[string]PlaceholderSource
+	lock    sync.RWMutex
+}
+
+func (r *placeholderRegistryImpl) Register(prefix string, source PlaceholderSource) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.sources[prefix] = source
+}
+
+func (r *placeholderRegistryImpl) Lookup(placeholder string) (PlaceholderSource, string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	matched := ""
+	for prefix := range r.sources {
+		if strings.HasPrefix(placeholder, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return nil, "", false
+	}
+	return r.sources[matched], placeholder[len(matched):], true
+}
+
+// randomPrefix is the prefix of random placeholders, which are the only cached placeholders
+const randomPrefix = "random."
+
+// placeholderResolver resolve the placeholders in property values, the values of random
+// source are cached by the property key, so the ${random.int} is stable for the key until
+// the key is changed. The values of other dynamic sources are resolved on each lookup, eg:
+// ${file:/run/secrets/db} will return the rotated secret.
+type placeholderResolver struct {
+	// lookup return the value of key with placeholders resolved
+	lookup func(key string, visiting map[string]bool) (string, error)
+
+	cache map[string]string
+	lock  sync.Mutex
+}
+
+func newPlaceholderResolver(lookup func(key string, visiting map[string]bool) (string, error)) *placeholderResolver {
+	return &placeholderResolver{
+		lookup: lookup,
+		cache:  make(map[string]string),
+	}
+}
+
+// evict remove the cached dynamic values of the changed keys
+func (r *placeholderResolver) evict(events []ChangeEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, event := range events {
+		for k := range r.cache {
+			if strings.HasPrefix(k, event.Key+"\x00") {
+				delete(r.cache, k)
+			}
+		}
+	}
+}
+
+// copyCache copy the cached dynamic values from other
+func (r *placeholderResolver) copyCache(other *placeholderResolver) {
+	other.lock.Lock()
+	defer other.lock.Unlock()
+
+	for k, v := range other.cache {
+		r.cache[k] = v
+	}
+}
+
+// resolve replace the placeholders in val of key, the placeholder is in form of:
+//  1. ${name}: the value of property name
+//  2. ${name:default}: the value of property name, or the default if it's not found
+//  3. ${prefix...}: the value of dynamic source registered with prefix, eg: ${env:HOME}
+//
+// The placeholders can be nested, eg: ${db.host:${env:DB_HOST}}. The $${ is escaped as the
+// literal ${, eg: $${name} is resolved as ${name}.
+func (r *placeholderResolver) resolve(key string, val string, visiting map[string]bool) (string, error) {
+	if !strings.Contains(val, "${") {
+		return val, nil
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(val, "${")
+		if start < 0 {
+			sb.WriteString(val)
+			break
+		}
+
+		if start > 0 && val[start-1] == '$' {
+			sb.WriteString(val[:start-1])
+			sb.WriteString("${")
+			val = val[start+2:]
+			continue
+		}
+
+		end := placeholderEnd(val, start)
+		if end < 0 {
+			return "", xerrors.Errorf("Cannot resolve placeholder in property with key '%v', the '${' is not closed, use '$${' for the literal '${'", key)
+		}
+
+		expr, err := r.resolve(key, val[start+2:end], visiting)
+		if err != nil {
+			return "", err
+		}
+		v, err := r.resolveExpr(key, expr, visiting)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteString(val[:start])
+		sb.WriteString(v)
+		val = val[end+1:]
+	}
+	return sb.String(), nil
+}
+
+// placeholderEnd return the index of '}' which closes the '${' at start, or -1
+func placeholderEnd(val string, start int) int {
+	depth := 0
+	for i := start; i < len(val); i++ {
+		switch {
+		case strings.HasPrefix(val[i:], "${"):
+			depth++
+			i++
+		case val[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (r *placeholderResolver) resolveExpr(key string, expr string, visiting map[string]bool) (string, error) {
+	if source, sexpr, ok := DefaultPlaceholderRegistry.Lookup(expr); ok {
+		if !strings.HasPrefix(expr, randomPrefix) {
+			v, err := source(sexpr)
+			if err != nil {
+				return "", placeholderErr(key, expr, err)
+			}
+			return v, nil
+		}
+
+		cacheKey := key + "\x00" + expr
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if v, ok := r.cache[cacheKey]; ok {
+			return v, nil
+		}
+
+		v, err := source(sexpr)
+		if err != nil {
+			return "", placeholderErr(key, expr, err)
+		}
+		r.cache[cacheKey] = v
+		return v, nil
+	}
+
+	name, def, hasDefault := splitDefault(expr)
+	if visiting[name] {
+		return "", xerrors.Errorf("Cannot resolve placeholder '${%v}' in property with key '%v', it's circular referenced", expr, key)
+	}
+
+	visiting[name] = true
+	defer delete(visiting, name)
+	v, err := r.lookup(name, visiting)
+	if err != nil {
+		if hasDefault && xerrors.Is(err, xerrors.ErrNotFound) {
+			return def, nil
+		}
+		return "", placeholderErr(key, expr, err)
+	}
+	return v, nil
+}
+
+// placeholderErr return the error of unresolved placeholder, the err is not wrapped, so the
+// property with unresolved placeholder will not been treated as not found.
+func placeholderErr(key string, expr string, err error) error {
+	return xerrors.Errorf("Cannot resolve placeholder '${%v}' in property with key '%v': %v", expr, key, err)
+}
+
+// splitDefault split the expr to name and default value, eg: host:localhost
+func splitDefault(expr string) (string, string, bool) {
+	kv := strings.SplitN(expr, ":", 2)
+	if len(kv) == 2 {
+		return kv[0], kv[1], true
+	}
+	return expr, "", false
+}
+
+func randomSource(expr string) (string, error) {
+	name, args := expr, ""
+	if idx := strings.Index(expr, "("); idx >= 0 && strings.HasSuffix(expr, ")") {
+		name, args = expr[:idx], expr[idx+1:len(expr)-1]
+	}
+
+	switch name {
+	case "int", "long":
+		min, max := int64(0), int64(1<<31-1)
+		if name == "long" {
+			max = 1<<63 - 1
+		}
+		if args != "" {
+			bounds := strings.Split(args, ",")
+			if len(bounds) > 2 {
+				return "", xerrors.Errorf("Cannot parse random range '%v'", args)
+			}
+
+			var err error
+			max, err = strconv.ParseInt(strings.TrimSpace(bounds[len(bounds)-1]), 10, 64)
+			if err != nil {
+				return "", xerrors.Wrapf(err, "Cannot parse random range '%v'", args)
+			}
+			if len(bounds) == 2 {
+				min, err = strconv.ParseInt(strings.TrimSpace(bounds[0]), 10, 64)
+				if err != nil {
+					return "", xerrors.Wrapf(err, "Cannot parse random range '%v'", args)
+				}
+			}
+		}
+		if min >= max {
+			return "", xerrors.Errorf("Cannot generate random %v in [%v, %v)", name, min, max)
+		}
+
+		n, err := rand.Int(rand.Reader, new(big.Int).Sub(big.NewInt(max), big.NewInt(min)))
+		if err != nil {
+			return "", xerrors.Wrapf(err, "Cannot generate random %v", name)
+		}
+		return strconv.FormatInt(n.Int64()+min, 10), nil
+	case "uuid":
+		b := make([]byte, 16)
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", xerrors.Wrapf(err, "Cannot generate random uuid")
+		}
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+	case "value":
+		b := make([]byte, 16)
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", xerrors.Wrapf(err, "Cannot generate random value")
+		}
+		return hex.EncodeToString(b), nil
+	}
+	return "", xerrors.Errorf("Cannot generate random value with unsupported type '%v'", expr)
+}
+
+func envSource(expr string) (string, error) {
+	name, def, hasDefault := splitDefault(expr)
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		if hasDefault {
+			return def, nil
+		}
+		return "", xerrors.WrapNotFound("env var '%v' not found", name)
+	}
+	return val, nil
+}
+
+func fileSource(expr string) (string, error) {
+	data, err := ioutil.ReadFile(expr)
+	if err != nil {
+		return "", xerrors.Wrapf(err, "Cannot read file '%v'", expr)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
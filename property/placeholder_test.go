@@ -0,0 +1,243 @@
+package property
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+func TestPlaceholder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuwa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := filepath.Join(dir, "db")
+	err = ioutil.WriteFile(secret, []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("NUWA_TEST_HOME", "/home/nuwa")
+	defer os.Unsetenv("NUWA_TEST_HOME")
+
+	type testCase struct {
+		desp   string
+		values map[string]string
+		key    string
+		err    string
+		expect string
+	}
+	testCases := []testCase{
+		{
+			desp: "reference",
+			values: map[string]string{
+				"db.host": "localhost",
+				"db.port": "3306",
+				"db.url":  "mysql://${db.host}:${db.port}/test",
+			},
+			key:    "db.url",
+			err:    "",
+			expect: "mysql://localhost:3306/test",
+		},
+		{
+			desp: "nested reference",
+			values: map[string]string{
+				"a": "${b}",
+				"b": "${c}",
+				"c": "v",
+			},
+			key:    "a",
+			err:    "",
+			expect: "v",
+		},
+		{
+			desp: "default value",
+			values: map[string]string{
+				"db.host": "${db.remote:${env:NUWA_TEST_MISSING:localhost}}",
+			},
+			key:    "db.host",
+			err:    "",
+			expect: "localhost",
+		},
+		{
+			desp: "env",
+			values: map[string]string{
+				"home": "${env:NUWA_TEST_HOME}/app",
+			},
+			key:    "home",
+			err:    "",
+			expect: "/home/nuwa/app",
+		},
+		{
+			desp: "file",
+			values: map[string]string{
+				"db.password": "${file:" + secret + "}",
+			},
+			key:    "db.password",
+			err:    "",
+			expect: "s3cr3t",
+		},
+		{
+			desp: "missing reference",
+			values: map[string]string{
+				"a": "${b}",
+			},
+			key:    "a",
+			err:    "Cannot resolve placeholder '\\${b}' in property with key 'a'",
+			expect: "",
+		},
+		{
+			desp: "missing env",
+			values: map[string]string{
+				"a": "${env:NUWA_TEST_MISSING}",
+			},
+			key:    "a",
+			err:    "env var 'NUWA_TEST_MISSING' not found",
+			expect: "",
+		},
+		{
+			desp: "circular reference",
+			values: map[string]string{
+				"a": "${b}",
+				"b": "${a}",
+			},
+			key:    "a",
+			err:    "it's circular referenced",
+			expect: "",
+		},
+		{
+			desp: "not closed",
+			values: map[string]string{
+				"a": "${b",
+			},
+			key:    "a",
+			err:    "the '\\${' is not closed",
+			expect: "",
+		},
+		{
+			desp: "escaped",
+			values: map[string]string{
+				"a": "$${b} and ${c}",
+				"c": "v",
+			},
+			key:    "a",
+			err:    "",
+			expect: "${b} and v",
+		},
+		{
+			desp: "escaped not closed",
+			values: map[string]string{
+				"a": "echo $${HOME",
+			},
+			key:    "a",
+			err:    "",
+			expect: "echo ${HOME",
+		},
+		{
+			desp: "unsupported random",
+			values: map[string]string{
+				"a": "${random.float}",
+			},
+			key:    "a",
+			err:    "unsupported type 'float'",
+			expect: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewCompositeProperties(propertiesImpl(tc.values))
+			actual, err := p.Get(tc.key)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeFalse())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestRandomPlaceholder(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewCompositeProperties(NewProperties())
+	err := p.Set("server", map[string]interface{}{
+		"port":  "${random.int(1000,2000)}",
+		"id":    "${random.uuid}",
+		"seed":  "${random.long}",
+		"token": "${random.value}",
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var port int
+	err = p.Retrive("server.port", &port)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(port).To(BeNumerically(">=", 1000))
+	g.Expect(port).To(BeNumerically("<", 2000))
+
+	// The random value is stable for the key
+	v, err := p.Get("server.port")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal(strconv.Itoa(port)))
+	g.Expect(p.Snapshot().Get("server.port")).To(Equal(v))
+
+	id, err := p.Get("server.id")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(id).To(MatchRegexp("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$"))
+	g.Expect(p.Get("server.token")).To(MatchRegexp("^[0-9a-f]{32}$"))
+	g.Expect(p.Get("server.seed")).To(MatchRegexp("^[0-9]+$"))
+
+	// The random value is regenerated after the key changed
+	err = p.Set("server.id", "${random.uuid}")
+	g.Expect(err).ToNot(HaveOccurred())
+	err = p.Set("server.id", "${random.uuid}-x")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p.Get("server.id")).ToNot(HavePrefix(id))
+
+	_, err = randomSource("int(2000,1000)")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot generate random int in [2000, 1000)"))
+	g.Expect(randomSource("int(1)")).To(Equal("0"))
+}
+
+func TestRegisterPlaceholderSource(t *testing.T) {
+	g := NewWithT(t)
+
+	RegisterPlaceholderSource("upper:", func(expr string) (string, error) {
+		return "UPPER-" + expr, nil
+	})
+	p := NewCompositeProperties(propertiesImpl(map[string]string{
+		"a": "${upper:x}",
+	}))
+	g.Expect(p.Get("a")).To(Equal("UPPER-x"))
+}
+
+func TestFilePlaceholderNotCached(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := filepath.Join(t.TempDir(), "db")
+	g.Expect(ioutil.WriteFile(secret, []byte("v1\n"), 0600)).ToNot(HaveOccurred())
+	p := NewCompositeProperties(propertiesImpl(map[string]string{
+		"db.password": "${file:" + secret + "}",
+	}))
+	v, err := p.Get("db.password")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("v1"))
+
+	// The rotated secret is resolved on next lookup
+	g.Expect(ioutil.WriteFile(secret, []byte("v2\n"), 0600)).ToNot(HaveOccurred())
+	v, err = p.Get("db.password")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("v2"))
+}
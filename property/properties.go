@@ -3,6 +3,9 @@ package property
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cast"
 
@@ -10,9 +13,10 @@ import (
 	"github.com/lsytj0413/nuwa/xerrors"
 )
 
-// NewProperties return the Properties impl
+// NewProperties return the Properties impl, which record the call site of Set as
+// the origin of values.
 func NewProperties() Properties {
-	return propertiesImpl(make(map[string]string))
+	return newTrackedProperties()
 }
 
 type propertiesImpl map[string]string
@@ -27,9 +31,27 @@ func (p propertiesImpl) Get(key string) (string, error) {
 }
 
 func (p propertiesImpl) Retrive(key string, i interface{}) error {
-	vstr, err := p.Get(key)
-	if err != nil {
-		return err
+	return retrive(p, key, i)
+}
+
+// retriveValue convert the vstr of key in p to the type of i, and set it to i.
+// The error will not contain the value if it's secret or resolved from placeholders.
+func retriveValue(p Properties, key string, vstr string, i interface{}) error {
+	err := convertValue(key, vstr, i)
+	if err != nil && hidesValue(p, key) {
+		target := i
+		if h, ok := i.(*hintedTarget); ok {
+			target = h.i
+		}
+		return xerrors.Errorf("Cannot convert the secret value with key '%v' to '%T'", key, utils.IndirectToInterface(target))
+	}
+	return err
+}
+
+func convertValue(key string, vstr string, i interface{}) error {
+	hint := ""
+	if h, ok := i.(*hintedTarget); ok {
+		i, hint = h.i, h.hint
 	}
 
 	v, err := utils.IndirectToSetableValue(i)
@@ -37,32 +59,64 @@ func (p propertiesImpl) Retrive(key string, i interface{}) error {
 		return err
 	}
 
+	// The target may be pointer to pointer, eg: **url.URL, the converter for
+	// pointer type is consulted before dereference.
+	if v.Kind() == reflect.Ptr {
+		if ok, err := retriveConverted(key, vstr, v); ok {
+			return err
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if hint != "" {
+		return retriveWithHint(key, vstr, v, hint)
+	}
+
+	ok, err := retriveConverted(key, vstr, v)
+	if ok {
+		return err
+	}
+
+	ok, err = retriveScalar(key, vstr, v)
+	if ok {
+		return err
+	}
+
 	switch v.Kind() {
+	// NOTE: the value is parsed with the bit size of target, so the out of range value
+	// is reported as overflow, eg: 128 for int8 or -1 for uint
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		u, err := cast.ToUint64E(vstr)
+		u, err := strconv.ParseUint(vstr, 0, v.Type().Bits())
 		if err != nil {
+			if isRangeErr(err) || isNegativeInt(vstr) {
+				return overflowErr(key, vstr, v)
+			}
 			return xerrors.Wrapf(err, "Cannot convert value '%v' to uint with key '%v'", vstr, key)
 		}
-
-		// NOTE: this value will be zero if the u overflow. FIX IT
 		v.SetUint(u)
 		return nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		u, err := cast.ToInt64E(vstr)
+		u, err := strconv.ParseInt(vstr, 0, v.Type().Bits())
 		if err != nil {
+			if isRangeErr(err) {
+				return overflowErr(key, vstr, v)
+			}
 			return xerrors.Wrapf(err, "Cannot convert value '%v' to int with key '%v'", vstr, key)
 		}
-
-		// NOTE: this value will be zero if the u overflow. FIX IT
 		v.SetInt(u)
 		return nil
 	case reflect.Float32, reflect.Float64:
-		u, err := cast.ToFloat64E(vstr)
+		u, err := strconv.ParseFloat(vstr, v.Type().Bits())
 		if err != nil {
+			if isRangeErr(err) {
+				return overflowErr(key, vstr, v)
+			}
 			return xerrors.Wrapf(err, "Cannot convert value '%v' to float with key '%v'", vstr, key)
 		}
-
-		// NOTE: this value will be zero if the u overflow. FIX IT
 		v.SetFloat(u)
 		return nil
 	case reflect.Bool:
@@ -80,6 +134,26 @@ func (p propertiesImpl) Retrive(key string, i interface{}) error {
 	return xerrors.Errorf("Cannot retrive value for key '%v', unsupported target type '%v'", key, v.Kind())
 }
 
+// overflowErr return the error for value which overflow the target type
+func overflowErr(key string, vstr string, v reflect.Value) error {
+	return xerrors.Errorf("Cannot convert value '%v' to '%v' with key '%v', it overflows the target type", vstr, v.Type(), key)
+}
+
+// isRangeErr return true if the err of strconv is caused by the value out of range
+func isRangeErr(err error) bool {
+	var numErr *strconv.NumError
+	return xerrors.As(err, &numErr) && numErr.Err == strconv.ErrRange
+}
+
+// isNegativeInt return true if the vstr is negative integer, which overflows the uint
+func isNegativeInt(vstr string) bool {
+	if !strings.HasPrefix(vstr, "-") {
+		return false
+	}
+	_, err := strconv.ParseInt(vstr, 0, 64)
+	return err == nil || isRangeErr(err)
+}
+
 func (p propertiesImpl) Set(key string, val interface{}) error {
 	switch v := reflect.ValueOf(val); v.Kind() {
 	case reflect.Map:
@@ -117,3 +191,63 @@ func (p propertiesImpl) Set(key string, val interface{}) error {
 
 	return nil
 }
+
+func (p propertiesImpl) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (p propertiesImpl) Has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+func (p propertiesImpl) Unset(key string) error {
+	found := false
+	for k := range p {
+		if IsSubKey(k, key) {
+			delete(p, k)
+			found = true
+		}
+	}
+
+	if !found {
+		return xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return nil
+}
+
+func (p propertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(p, prefix)
+}
+
+func (p propertiesImpl) Snapshot() Properties {
+	s := propertiesImpl(make(map[string]string, len(p)))
+	for k, v := range p {
+		s[k] = v
+	}
+	return newReadOnlyProperties(s)
+}
+
+func (p propertiesImpl) Origin(key string) (Origin, error) {
+	if !p.Has(key) {
+		return Origin{}, xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return Origin{
+		Source: OriginSourceUnknown,
+	}, nil
+}
+
+// IsSubKey return true if the key is prefix or the sub key of prefix, eg: prefix.sub or prefix[0]
+func IsSubKey(key string, prefix string) bool {
+	if !strings.HasPrefix(key, prefix) {
+		return false
+	}
+
+	rest := key[len(prefix):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
@@ -1,6 +1,7 @@
 package property
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -222,7 +223,7 @@ func TestSet(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.desp, func(t *testing.T) {
 			g := NewWithT(t)
-			p := NewProperties().(propertiesImpl)
+			p := propertiesImpl(make(map[string]string))
 			err := p.Set(tc.key, tc.value)
 			if tc.err != "" {
 				g.Expect(err).To(HaveOccurred())
@@ -615,6 +616,457 @@ func TestRetrive(t *testing.T) {
 	}
 }
 
+func TestRetriveNumberRange(t *testing.T) {
+	type testCase struct {
+		desp   string
+		value  string
+		i      interface{}
+		err    string
+		expect interface{}
+	}
+	testCases := []testCase{
+		{
+			desp:  "int8 min",
+			value: "-128",
+			i:     new(int8),
+			err:   "",
+			expect: func() interface{} {
+				var i int8 = -128
+				return &i
+			}(),
+		},
+		{
+			desp:  "int8 max",
+			value: "127",
+			i:     new(int8),
+			err:   "",
+			expect: func() interface{} {
+				var i int8 = 127
+				return &i
+			}(),
+		},
+		{
+			desp:   "int8 overflow",
+			value:  "128",
+			i:      new(int8),
+			err:    "Cannot convert value '128' to 'int8' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "int8 underflow",
+			value:  "-129",
+			i:      new(int8),
+			err:    "Cannot convert value '-129' to 'int8' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "int16 min",
+			value: "-32768",
+			i:     new(int16),
+			err:   "",
+			expect: func() interface{} {
+				var i int16 = -32768
+				return &i
+			}(),
+		},
+		{
+			desp:  "int16 max",
+			value: "32767",
+			i:     new(int16),
+			err:   "",
+			expect: func() interface{} {
+				var i int16 = 32767
+				return &i
+			}(),
+		},
+		{
+			desp:   "int16 overflow",
+			value:  "32768",
+			i:      new(int16),
+			err:    "Cannot convert value '32768' to 'int16' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "int16 underflow",
+			value:  "-32769",
+			i:      new(int16),
+			err:    "Cannot convert value '-32769' to 'int16' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "int32 min",
+			value: "-2147483648",
+			i:     new(int32),
+			err:   "",
+			expect: func() interface{} {
+				var i int32 = -2147483648
+				return &i
+			}(),
+		},
+		{
+			desp:  "int32 max",
+			value: "2147483647",
+			i:     new(int32),
+			err:   "",
+			expect: func() interface{} {
+				var i int32 = 2147483647
+				return &i
+			}(),
+		},
+		{
+			desp:   "int32 overflow",
+			value:  "2147483648",
+			i:      new(int32),
+			err:    "Cannot convert value '2147483648' to 'int32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "int32 underflow",
+			value:  "-2147483649",
+			i:      new(int32),
+			err:    "Cannot convert value '-2147483649' to 'int32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "int64 min",
+			value: "-9223372036854775808",
+			i:     new(int64),
+			err:   "",
+			expect: func() interface{} {
+				var i int64 = math.MinInt64
+				return &i
+			}(),
+		},
+		{
+			desp:  "int64 max",
+			value: "9223372036854775807",
+			i:     new(int64),
+			err:   "",
+			expect: func() interface{} {
+				var i int64 = math.MaxInt64
+				return &i
+			}(),
+		},
+		{
+			desp:   "int64 overflow",
+			value:  "9223372036854775808",
+			i:      new(int64),
+			err:    "Cannot convert value '9223372036854775808' to 'int64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "int64 underflow",
+			value:  "-9223372036854775809",
+			i:      new(int64),
+			err:    "Cannot convert value '-9223372036854775809' to 'int64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "int min",
+			value: "-9223372036854775808",
+			i:     new(int),
+			err:   "",
+			expect: func() interface{} {
+				var i int = math.MinInt64
+				return &i
+			}(),
+		},
+		{
+			desp:  "int max",
+			value: "9223372036854775807",
+			i:     new(int),
+			err:   "",
+			expect: func() interface{} {
+				var i int = math.MaxInt64
+				return &i
+			}(),
+		},
+		{
+			desp:   "int overflow",
+			value:  "9223372036854775808",
+			i:      new(int),
+			err:    "Cannot convert value '9223372036854775808' to 'int' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "int underflow",
+			value:  "-9223372036854775809",
+			i:      new(int),
+			err:    "Cannot convert value '-9223372036854775809' to 'int' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "uint8 min",
+			value: "0",
+			i:     new(uint8),
+			err:   "",
+			expect: func() interface{} {
+				var i uint8 = 0
+				return &i
+			}(),
+		},
+		{
+			desp:  "uint8 max",
+			value: "255",
+			i:     new(uint8),
+			err:   "",
+			expect: func() interface{} {
+				var i uint8 = 255
+				return &i
+			}(),
+		},
+		{
+			desp:   "uint8 overflow",
+			value:  "256",
+			i:      new(uint8),
+			err:    "Cannot convert value '256' to 'uint8' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint8 negative",
+			value:  "-1",
+			i:      new(uint8),
+			err:    "Cannot convert value '-1' to 'uint8' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "uint16 min",
+			value: "0",
+			i:     new(uint16),
+			err:   "",
+			expect: func() interface{} {
+				var i uint16 = 0
+				return &i
+			}(),
+		},
+		{
+			desp:  "uint16 max",
+			value: "65535",
+			i:     new(uint16),
+			err:   "",
+			expect: func() interface{} {
+				var i uint16 = 65535
+				return &i
+			}(),
+		},
+		{
+			desp:   "uint16 overflow",
+			value:  "65536",
+			i:      new(uint16),
+			err:    "Cannot convert value '65536' to 'uint16' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint16 negative",
+			value:  "-1",
+			i:      new(uint16),
+			err:    "Cannot convert value '-1' to 'uint16' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "uint32 min",
+			value: "0",
+			i:     new(uint32),
+			err:   "",
+			expect: func() interface{} {
+				var i uint32 = 0
+				return &i
+			}(),
+		},
+		{
+			desp:  "uint32 max",
+			value: "4294967295",
+			i:     new(uint32),
+			err:   "",
+			expect: func() interface{} {
+				var i uint32 = 4294967295
+				return &i
+			}(),
+		},
+		{
+			desp:   "uint32 overflow",
+			value:  "4294967296",
+			i:      new(uint32),
+			err:    "Cannot convert value '4294967296' to 'uint32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint32 negative",
+			value:  "-1",
+			i:      new(uint32),
+			err:    "Cannot convert value '-1' to 'uint32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "uint64 min",
+			value: "0",
+			i:     new(uint64),
+			err:   "",
+			expect: func() interface{} {
+				var i uint64 = 0
+				return &i
+			}(),
+		},
+		{
+			desp:  "uint64 max",
+			value: "18446744073709551615",
+			i:     new(uint64),
+			err:   "",
+			expect: func() interface{} {
+				var i uint64 = 18446744073709551615
+				return &i
+			}(),
+		},
+		{
+			desp:   "uint64 overflow",
+			value:  "18446744073709551616",
+			i:      new(uint64),
+			err:    "Cannot convert value '18446744073709551616' to 'uint64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint64 negative",
+			value:  "-1",
+			i:      new(uint64),
+			err:    "Cannot convert value '-1' to 'uint64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "uint min",
+			value: "0",
+			i:     new(uint),
+			err:   "",
+			expect: func() interface{} {
+				var i uint = 0
+				return &i
+			}(),
+		},
+		{
+			desp:  "uint max",
+			value: "18446744073709551615",
+			i:     new(uint),
+			err:   "",
+			expect: func() interface{} {
+				var i uint = 18446744073709551615
+				return &i
+			}(),
+		},
+		{
+			desp:   "uint overflow",
+			value:  "18446744073709551616",
+			i:      new(uint),
+			err:    "Cannot convert value '18446744073709551616' to 'uint' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint negative",
+			value:  "-1",
+			i:      new(uint),
+			err:    "Cannot convert value '-1' to 'uint' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "float32 max",
+			value: "3.4028234663852886e+38",
+			i:     new(float32),
+			err:   "",
+			expect: func() interface{} {
+				var i float32 = math.MaxFloat32
+				return &i
+			}(),
+		},
+		{
+			desp:  "float32 smallest",
+			value: "1.401298464324817e-45",
+			i:     new(float32),
+			err:   "",
+			expect: func() interface{} {
+				var i float32 = math.SmallestNonzeroFloat32
+				return &i
+			}(),
+		},
+		{
+			desp:   "float32 overflow",
+			value:  "3.5e+38",
+			i:      new(float32),
+			err:    "Cannot convert value '3.5e+38' to 'float32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "float32 negative overflow",
+			value:  "-3.5e+38",
+			i:      new(float32),
+			err:    "Cannot convert value '-3.5e+38' to 'float32' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:  "float64 max",
+			value: "1.7976931348623157e+308",
+			i:     new(float64),
+			err:   "",
+			expect: func() interface{} {
+				var i float64 = math.MaxFloat64
+				return &i
+			}(),
+		},
+		{
+			desp:   "float64 overflow",
+			value:  "1.8e+308",
+			i:      new(float64),
+			err:    "Cannot convert value '1.8e+308' to 'float64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "float64 negative overflow",
+			value:  "-1.8e+308",
+			i:      new(float64),
+			err:    "Cannot convert value '-1.8e+308' to 'float64' with key 'k1', it overflows the target type",
+			expect: nil,
+		},
+		{
+			desp:   "uint negative float",
+			value:  "-1.5",
+			i:      new(uint),
+			err:    "Cannot convert value '-1.5' to uint with key 'k1'",
+			expect: nil,
+		},
+		{
+			desp:   "int8 invalid",
+			value:  "1x",
+			i:      new(int8),
+			err:    "Cannot convert value '1x' to int with key 'k1'",
+			expect: nil,
+		},
+		{
+			desp:  "int8 hex",
+			value: "0x7f",
+			i:     new(int8),
+			err:   "",
+			expect: func() interface{} {
+				var i int8 = 127
+				return &i
+			}(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			p := propertiesImpl(map[string]string{
+				"k1": tc.value,
+			})
+			err := p.Retrive("k1", tc.i)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(HavePrefix(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(tc.i).To(Equal(tc.expect))
+		})
+	}
+}
+
 func TestReflect(t *testing.T) {
 	var p int
 	g := NewWithT(t)
@@ -660,3 +1112,94 @@ func TestReflect(t *testing.T) {
 	v.Set(reflect.ValueOf(int(1)))
 	g.Expect(st2.V).To(Equal(int(1)))
 }
+
+func TestKeysHasUnset(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewProperties()
+	err := p.Set("db", map[string]interface{}{
+		"host":  "localhost",
+		"port":  3306,
+		"hosts": []string{"a", "b"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	err = p.Set("dbx", "x")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(p.Keys()).To(Equal([]string{"db.host", "db.hosts[0]", "db.hosts[1]", "db.port", "dbx"}))
+	g.Expect(p.Has("db.host")).To(BeTrue())
+	g.Expect(p.Has("db")).To(BeFalse())
+
+	err = p.Unset("db.hosts")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p.Keys()).To(Equal([]string{"db.host", "db.port", "dbx"}))
+
+	err = p.Unset("db")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p.Keys()).To(Equal([]string{"dbx"}))
+
+	err = p.Unset("db")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+}
+
+func TestSub(t *testing.T) {
+	g := NewWithT(t)
+
+	p := propertiesImpl(map[string]string{
+		"db.host":         "localhost",
+		"db.port":         "3306",
+		"db.replica.host": "replica",
+		"dbx":             "x",
+	})
+	db := p.Sub("db")
+	g.Expect(db.Keys()).To(Equal([]string{"host", "port", "replica.host"}))
+	g.Expect(db.Has("host")).To(BeTrue())
+	g.Expect(db.Has("x")).To(BeFalse())
+
+	v, err := db.Get("host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("localhost"))
+
+	var port int
+	err = db.Retrive("port", &port)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(port).To(Equal(3306))
+
+	replica := db.Sub("replica.")
+	v, err = replica.Get("host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("replica"))
+
+	err = replica.Set("port", 3307)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p["db.replica.port"]).To(Equal("3307"))
+
+	err = db.Unset("replica")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(p.Keys()).To(Equal([]string{"db.host", "db.port", "dbx"}))
+
+	// The empty key is the prefix itself
+	cfg := struct {
+		Host string
+		Port int
+	}{}
+	err = db.Retrive("", &cfg)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cfg.Host).To(Equal("localhost"))
+	g.Expect(cfg.Port).To(Equal(3306))
+	dbx := p.Sub("dbx")
+	g.Expect(dbx.Has("")).To(BeTrue())
+	v, err = dbx.Get("")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("x"))
+	err = dbx.Unset("")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(dbx.Has("")).To(BeFalse())
+	g.Expect(p.Keys()).To(Equal([]string{"db.host", "db.port"}))
+
+	// The empty prefix is the root
+	root := p.Sub("")
+	g.Expect(root.Keys()).To(Equal([]string{"db.host", "db.port"}))
+	g.Expect(root.Sub("db").Keys()).To(Equal([]string{"host", "port"}))
+}
@@ -0,0 +1,198 @@
+package property
+
+import (
+	"encoding"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// Hint is the hint for how to convert the property value to the target
+type Hint = string
+
+const (
+	// HintByteSize identifier the value is human byte size, eg: 512MiB, the target must be integer
+	HintByteSize Hint = "bytesize"
+)
+
+// WithHint return the target of Properties.Retrive with hint, eg:
+//
+//	var size int64
+//	p.Retrive("buffer.size", property.WithHint(&size, property.HintByteSize))
+func WithHint(i interface{}, hint Hint) interface{} {
+	return &hintedTarget{
+		i:    i,
+		hint: hint,
+	}
+}
+
+type hintedTarget struct {
+	i    interface{}
+	hint Hint
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	regexpType   = reflect.TypeOf(regexp.Regexp{})
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// retriveScalar convert the vstr to the rich scalar types, it will return false if the
+// type of v is not supported.
+func retriveScalar(key string, vstr string, v reflect.Value) (bool, error) {
+	switch v.Type() {
+	case durationType:
+		d, err := cast.ToDurationE(vstr)
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot convert value '%v' to duration with key '%v'", vstr, key)
+		}
+		v.SetInt(int64(d))
+		return true, nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, vstr)
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot convert value '%v' to time with key '%v'", vstr, key)
+		}
+		v.Set(reflect.ValueOf(t))
+		return true, nil
+	case urlType:
+		u, err := url.Parse(vstr)
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot convert value '%v' to url with key '%v'", vstr, key)
+		}
+		v.Set(reflect.ValueOf(*u))
+		return true, nil
+	case ipType:
+		ip := net.ParseIP(vstr)
+		if ip == nil {
+			return true, xerrors.Errorf("Cannot convert value '%v' to ip with key '%v'", vstr, key)
+		}
+		v.Set(reflect.ValueOf(ip))
+		return true, nil
+	case ipNetType:
+		_, ipNet, err := net.ParseCIDR(vstr)
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot convert value '%v' to ip network with key '%v'", vstr, key)
+		}
+		v.Set(reflect.ValueOf(*ipNet))
+		return true, nil
+	case regexpType:
+		re, err := regexp.Compile(vstr)
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot convert value '%v' to regexp with key '%v'", vstr, key)
+		}
+		v.Set(reflect.ValueOf(re).Elem())
+		return true, nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(vstr))
+		if err != nil {
+			return true, xerrors.Wrapf(err, "Cannot unmarshal value '%v' to '%v' with key '%v'", vstr, v.Type(), key)
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// retriveWithHint convert the vstr to v with the hint
+func retriveWithHint(key string, vstr string, v reflect.Value, hint Hint) error {
+	switch hint {
+	case HintByteSize:
+		size, err := ParseByteSize(vstr)
+		if err != nil {
+			return xerrors.Wrapf(err, "Cannot convert value '%v' to byte size with key '%v'", vstr, key)
+		}
+
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.OverflowUint(size) {
+				return overflowErr(key, vstr, v)
+			}
+			v.SetUint(size)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if size > math.MaxInt64 || v.OverflowInt(int64(size)) {
+				return overflowErr(key, vstr, v)
+			}
+			v.SetInt(int64(size))
+			return nil
+		}
+		return xerrors.Errorf("Cannot retrive byte size for key '%v', the target type '%v' must be integer", key, v.Type())
+	}
+
+	return xerrors.Errorf("Cannot retrive value for key '%v', unsupported hint '%v'", key, hint)
+}
+
+var byteSizeUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"k":   1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tib": 1 << 40,
+	"p":   1 << 50,
+	"pib": 1 << 50,
+}
+
+// ParseByteSize parse the human byte size to bytes, the unit is case-insensitive:
+//  1. B, KB, MB, GB, TB, PB: the decimal units, eg: 1KB = 1000B
+//  2. K(iB), M(iB), G(iB), T(iB), P(iB): the binary units, eg: 1KiB = 1K = 1024B
+//
+// The value without unit is bytes, and the number may be fractional, eg: 1.5GiB.
+func ParseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if idx < 0 {
+		idx = len(s)
+	}
+
+	num, unit := s[:idx], strings.ToLower(strings.TrimSpace(s[idx:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, xerrors.Errorf("unknown byte size unit '%v'", s[idx:])
+	}
+
+	if n, err := strconv.ParseUint(num, 10, 64); err == nil {
+		if n > math.MaxUint64/multiplier {
+			return 0, xerrors.Errorf("byte size '%v' overflows uint64", s)
+		}
+		return n * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid byte size '%v'", s)
+	}
+	size := f * float64(multiplier)
+	if size >= math.MaxUint64 {
+		return 0, xerrors.Errorf("byte size '%v' overflows uint64", s)
+	}
+	return uint64(size), nil
+}
@@ -0,0 +1,262 @@
+package property
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return xerrors.Errorf("unknown level '%s'", text)
+	}
+	return nil
+}
+
+func TestRetriveScalar(t *testing.T) {
+	type testConfig struct {
+		Timeout  time.Duration
+		StartAt  time.Time
+		Endpoint *url.URL
+		IP       net.IP
+		Network  net.IPNet
+		Pattern  *regexp.Regexp
+		Level    testLevel
+	}
+	type testCase struct {
+		desp   string
+		value  string
+		target func(c *testConfig) interface{}
+		err    string
+		expect func(g *WithT, c *testConfig)
+	}
+	testCases := []testCase{
+		{
+			desp:  "normal duration",
+			value: "30s",
+			target: func(c *testConfig) interface{} {
+				return &c.Timeout
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.Timeout).To(Equal(30 * time.Second))
+			},
+		},
+		{
+			desp:  "invalid duration",
+			value: "30x",
+			target: func(c *testConfig) interface{} {
+				return &c.Timeout
+			},
+			err: "Cannot convert value '30x' to duration with key 'k1'",
+		},
+		{
+			desp:  "normal time",
+			value: "2021-10-01T08:00:00Z",
+			target: func(c *testConfig) interface{} {
+				return &c.StartAt
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.StartAt).To(Equal(time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			desp:  "normal url",
+			value: "https://example.com:8080/api?x=1",
+			target: func(c *testConfig) interface{} {
+				return &c.Endpoint
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.Endpoint.Host).To(Equal("example.com:8080"))
+				g.Expect(c.Endpoint.Path).To(Equal("/api"))
+			},
+		},
+		{
+			desp:  "normal ip",
+			value: "192.168.1.1",
+			target: func(c *testConfig) interface{} {
+				return &c.IP
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.IP.Equal(net.IPv4(192, 168, 1, 1))).To(BeTrue())
+			},
+		},
+		{
+			desp:  "invalid ip",
+			value: "192.168.1",
+			target: func(c *testConfig) interface{} {
+				return &c.IP
+			},
+			err: "Cannot convert value '192.168.1' to ip with key 'k1'",
+		},
+		{
+			desp:  "normal ip network",
+			value: "10.0.0.0/8",
+			target: func(c *testConfig) interface{} {
+				return &c.Network
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.Network.String()).To(Equal("10.0.0.0/8"))
+			},
+		},
+		{
+			desp:  "normal regexp",
+			value: "^a+$",
+			target: func(c *testConfig) interface{} {
+				return &c.Pattern
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.Pattern.MatchString("aaa")).To(BeTrue())
+			},
+		},
+		{
+			desp:  "invalid regexp",
+			value: "^a(",
+			target: func(c *testConfig) interface{} {
+				return &c.Pattern
+			},
+			err: "Cannot convert value '\\^a\\(' to regexp with key 'k1'",
+		},
+		{
+			desp:  "normal text unmarshaler",
+			value: "INFO",
+			target: func(c *testConfig) interface{} {
+				return &c.Level
+			},
+			expect: func(g *WithT, c *testConfig) {
+				g.Expect(c.Level).To(Equal(testLevel(1)))
+			},
+		},
+		{
+			desp:  "invalid text unmarshaler",
+			value: "trace",
+			target: func(c *testConfig) interface{} {
+				return &c.Level
+			},
+			err: "Cannot unmarshal value 'trace' to 'property.testLevel' with key 'k1': unknown level 'trace'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			p := propertiesImpl(map[string]string{
+				"k1": tc.value,
+			})
+			c := &testConfig{}
+			err := p.Retrive("k1", tc.target(c))
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(MatchRegexp(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			tc.expect(g, c)
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	type testCase struct {
+		desp   string
+		value  string
+		err    string
+		expect uint64
+	}
+	testCases := []testCase{
+		{
+			desp:   "bytes without unit",
+			value:  "1024",
+			expect: 1024,
+		},
+		{
+			desp:   "decimal unit",
+			value:  "2KB",
+			expect: 2000,
+		},
+		{
+			desp:   "binary unit",
+			value:  "512MiB",
+			expect: 512 << 20,
+		},
+		{
+			desp:   "short binary unit with space",
+			value:  " 1 g ",
+			expect: 1 << 30,
+		},
+		{
+			desp:   "fractional",
+			value:  "1.5GiB",
+			expect: 3 << 29,
+		},
+		{
+			desp:  "unknown unit",
+			value: "1XB",
+			err:   "unknown byte size unit 'XB'",
+		},
+		{
+			desp:  "invalid number",
+			value: "1.2.3MB",
+			err:   "invalid byte size '1.2.3MB'",
+		},
+		{
+			desp:  "overflow",
+			value: "20000PiB",
+			err:   "byte size '20000PiB' overflows uint64",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, err := ParseByteSize(tc.value)
+			if tc.err != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(Equal(tc.err))
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestRetriveWithHint(t *testing.T) {
+	g := NewWithT(t)
+	p := propertiesImpl(map[string]string{
+		"size":  "512MiB",
+		"small": "1KiB",
+	})
+
+	var size int64
+	err := p.Retrive("size", WithHint(&size, HintByteSize))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(size).To(Equal(int64(512 << 20)))
+
+	var small uint8
+	err = p.Retrive("small", WithHint(&small, HintByteSize))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot convert value '1KiB' to 'uint8' with key 'small', it overflows the target type"))
+
+	var s string
+	err = p.Retrive("size", WithHint(&s, HintByteSize))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot retrive byte size for key 'size', the target type 'string' must be integer"))
+
+	err = p.Retrive("size", WithHint(&size, "unknown"))
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot retrive value for key 'size', unsupported hint 'unknown'"))
+}
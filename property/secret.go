@@ -0,0 +1,201 @@
+package property
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// Decryptor decrypt the cipher text of encrypted property value, the value is encrypted
+// if it's in form of {cipher}xxx or ENC(xxx), and the xxx is the cipher text.
+type Decryptor interface {
+	// Decrypt return the plain text of cipher text
+	Decrypt(ciphertext string) (string, error)
+}
+
+// Cipher is the Decryptor which can also encrypt the plain text.
+type Cipher interface {
+	Decryptor
+
+	// Encrypt return the cipher text of plain text
+	Encrypt(plaintext string) (string, error)
+}
+
+// ParseEncrypted return the cipher text of the encrypted value, it will return false if
+// the value is not encrypted.
+func ParseEncrypted(val string) (string, bool) {
+	switch {
+	case strings.HasPrefix(val, "{cipher}"):
+		return strings.TrimPrefix(val, "{cipher}"), true
+	case strings.HasPrefix(val, "ENC(") && strings.HasSuffix(val, ")"):
+		return val[len("ENC(") : len(val)-1], true
+	}
+	return "", false
+}
+
+// NewAESGCMCipher return the Cipher with AES-GCM, the key must be 16, 24 or 32 bytes to
+// select AES-128, AES-192 or AES-256. The cipher text is base64 encoded of nonce and sealed data.
+func NewAESGCMCipher(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot create AES cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot create GCM cipher")
+	}
+	return &aesGCMCipher{
+		aead: aead,
+	}, nil
+}
+
+// NewAESGCMCipherFromFile return the AES-GCM Cipher with key in the file, the key is
+// base64 encoded, and the blank around it is ignored.
+func NewAESGCMCipherFromFile(path string) (Cipher, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot read key file '%v'", path)
+	}
+	return newAESGCMCipherFromBase64(string(data))
+}
+
+// NewAESGCMCipherFromEnv return the AES-GCM Cipher with key in the env var, the key is base64 encoded.
+func NewAESGCMCipherFromEnv(name string) (Cipher, error) {
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, xerrors.WrapNotFound("env var '%v' not found", name)
+	}
+	return newAESGCMCipherFromBase64(val)
+}
+
+func newAESGCMCipherFromBase64(s string) (Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "Cannot decode key with base64")
+	}
+	return NewAESGCMCipher(key)
+}
+
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+func (c *aesGCMCipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", xerrors.Wrapf(err, "Cannot generate nonce")
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *aesGCMCipher) Decrypt(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", xerrors.Wrapf(err, "Cannot decode cipher text with base64")
+	}
+
+	if len(data) < c.aead.NonceSize() {
+		return "", xerrors.Errorf("Cannot decrypt cipher text, it's too short")
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", xerrors.Wrapf(err, "Cannot decrypt cipher text")
+	}
+	return string(plaintext), nil
+}
+
+// MaskedValue is the replacement of secret value in dump and error message
+const MaskedValue = "******"
+
+// SecretKeyPatterns is the patterns of secret key, the key is secret if it contains
+// any of the patterns case-insensitively.
+var SecretKeyPatterns = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"credential",
+	"apikey",
+	"api_key",
+	"api-key",
+	"privatekey",
+	"private_key",
+	"private-key",
+}
+
+// IsSecretKey return true if the value of key is secret, which is the key matches
+// SecretKeyPatterns. The value of key is also treated as secret where it's encrypted.
+func IsSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, pattern := range SecretKeyPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskValue return MaskedValue if the key is secret, otherwise return the val.
+func MaskValue(key string, val string) string {
+	if IsSecretKey(key) {
+		return MaskedValue
+	}
+	return val
+}
+
+// isSecret return true if the key is secret or the raw value of key in p is encrypted, the
+// encrypted value is checked with p, so it will not affect the same key in other Properties
+func isSecret(p Properties, key string) bool {
+	if IsSecretKey(key) {
+		return true
+	}
+
+	raw, err := GetRaw(p, key)
+	if err != nil {
+		return false
+	}
+	_, ok := ParseEncrypted(raw)
+	return ok
+}
+
+// hidesValue return true if the value of key in p should not been printed in error message,
+// which is secret or resolved from placeholders, the placeholders may interpolate secrets,
+// eg: pg://u:${db.password}@localhost
+func hidesValue(p Properties, key string) bool {
+	if isSecret(p, key) {
+		return true
+	}
+
+	raw, err := GetRaw(p, key)
+	return err == nil && strings.Contains(raw, "${")
+}
+
+// decrypt return the plain text of val if it's encrypted
+func decrypt(key string, val string, d Decryptor) (string, error) {
+	ciphertext, ok := ParseEncrypted(val)
+	if !ok {
+		return val, nil
+	}
+
+	if d == nil {
+		return "", xerrors.Errorf("Cannot decrypt property with key '%v', no decryptor", key)
+	}
+
+	plaintext, err := d.Decrypt(ciphertext)
+	if err != nil {
+		return "", xerrors.Wrapf(err, "Cannot decrypt property with key '%v'", key)
+	}
+	return plaintext, nil
+}
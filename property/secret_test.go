@@ -0,0 +1,173 @@
+package property
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseEncrypted(t *testing.T) {
+	type testCase struct {
+		desp      string
+		val       string
+		encrypted bool
+		expect    string
+	}
+	testCases := []testCase{
+		{
+			desp:      "cipher prefix",
+			val:       "{cipher}abc",
+			encrypted: true,
+			expect:    "abc",
+		},
+		{
+			desp:      "enc wrapper",
+			val:       "ENC(abc)",
+			encrypted: true,
+			expect:    "abc",
+		},
+		{
+			desp:      "plain value",
+			val:       "ENC(abc",
+			encrypted: false,
+			expect:    "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desp, func(t *testing.T) {
+			g := NewWithT(t)
+			actual, ok := ParseEncrypted(tc.val)
+			g.Expect(ok).To(Equal(tc.encrypted))
+			g.Expect(actual).To(Equal(tc.expect))
+		})
+	}
+}
+
+func TestAESGCMCipher(t *testing.T) {
+	g := NewWithT(t)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	c, err := NewAESGCMCipher(key)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	ciphertext, err := c.Encrypt("s3cr3t")
+	g.Expect(err).ToNot(HaveOccurred())
+	plaintext, err := c.Decrypt(ciphertext)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plaintext).To(Equal("s3cr3t"))
+
+	_, err = c.Decrypt(base64.StdEncoding.EncodeToString([]byte("short")))
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewAESGCMCipher([]byte("short"))
+	g.Expect(err).To(HaveOccurred())
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key")
+	err = ioutil.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	fc, err := NewAESGCMCipherFromFile(path)
+	g.Expect(err).ToNot(HaveOccurred())
+	plaintext, err = fc.Decrypt(ciphertext)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plaintext).To(Equal("s3cr3t"))
+
+	os.Setenv("NUWA_TEST_KEY", base64.StdEncoding.EncodeToString(key))
+	defer os.Unsetenv("NUWA_TEST_KEY")
+	ec, err := NewAESGCMCipherFromEnv("NUWA_TEST_KEY")
+	g.Expect(err).ToNot(HaveOccurred())
+	plaintext, err = ec.Decrypt(ciphertext)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(plaintext).To(Equal("s3cr3t"))
+
+	_, err = NewAESGCMCipherFromEnv("NUWA_TEST_KEY_MISSING")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCompositeDecrypt(t *testing.T) {
+	g := NewWithT(t)
+
+	c, err := NewAESGCMCipher([]byte("0123456789abcdef"))
+	g.Expect(err).ToNot(HaveOccurred())
+	ciphertext, err := c.Encrypt("3306")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	p := NewCompositeProperties(propertiesImpl(map[string]string{
+		"db.port":     "{cipher}" + ciphertext,
+		"db.host":     "ENC(" + ciphertext + ")",
+		"db.password": "abc",
+		"db.user":     "ENC(invalid)",
+	}))
+	_, err = p.Get("db.port")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot decrypt property with key 'db.port', no decryptor"))
+
+	p.SetDecryptor(c)
+	v, err := p.Get("db.host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("3306"))
+
+	var port int
+	err = p.Retrive("db.port", &port)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(port).To(Equal(3306))
+
+	_, err = p.Get("db.user")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(HavePrefix("Cannot decrypt property with key 'db.user'"))
+
+	// The secret value is masked in error message
+	var b bool
+	err = p.Retrive("db.host", &b)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot convert the secret value with key 'db.host' to '*bool'"))
+	err = p.Retrive("db.password", &port)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).ToNot(ContainSubstring("abc"))
+}
+
+func TestSecretInPlaceholder(t *testing.T) {
+	g := NewWithT(t)
+
+	p := NewCompositeProperties(propertiesImpl(map[string]string{
+		"db.password": "hunter2",
+		"dsn":         "pg://u:${db.password}@h:%zz",
+		"app.name":    "${db.password}",
+	}))
+
+	// The value resolved from placeholders may interpolate secrets, it's hidden in error message
+	var u url.URL
+	err := p.Retrive("dsn", &u)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(Equal("Cannot convert the secret value with key 'dsn' to '*url.URL'"))
+
+	var app struct {
+		Name string `validate:"oneof=api worker"`
+	}
+	err = p.Retrive("app", &app)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).ToNot(ContainSubstring("hunter2"))
+	g.Expect(err.Error()).To(ContainSubstring("does not satisfy rule 'oneof=api worker'"))
+}
+
+func TestMaskValue(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(MaskValue("db.Password", "abc")).To(Equal(MaskedValue))
+	g.Expect(MaskValue("github.api-key", "abc")).To(Equal(MaskedValue))
+	g.Expect(MaskValue("server.host", "abc")).To(Equal("abc"))
+	g.Expect(ValidateValue("auth.token", "abc", true, "oneof=a b")).To(Equal(ValidationErrors{
+		{
+			Key:     "auth.token",
+			Message: "does not satisfy rule 'oneof=a b'",
+		},
+	}))
+}
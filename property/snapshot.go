@@ -0,0 +1,226 @@
+package property
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// ErrReadOnly defines the Properties is read-only, eg: the snapshot
+var ErrReadOnly = xerrors.New("read-only")
+
+// RawGetter is implemented by the Properties which decrypt the values or resolve the
+// placeholders in Get, the wrappers of Properties should implement it too, otherwise the
+// secrets can't been recognized and masked by Entries.
+type RawGetter interface {
+	// GetRaw return the value of key as it's stored, without decryption and placeholder
+	// resolution, eg: ENC(xxx) or pg://${db.user}@localhost
+	GetRaw(key string) (string, error)
+}
+
+// GetRaw return the value of key from p without decryption and placeholder resolution,
+// it's the same as p.Get if p is not RawGetter.
+func GetRaw(p Properties, key string) (string, error) {
+	if r, ok := p.(RawGetter); ok {
+		return r.GetRaw(key)
+	}
+	return p.Get(key)
+}
+
+// newReadOnlyProperties return the view of p which cannot been changed
+func newReadOnlyProperties(p Properties) Properties {
+	return &readOnlyPropertiesImpl{
+		Properties: p,
+	}
+}
+
+type readOnlyPropertiesImpl struct {
+	Properties
+}
+
+func (r *readOnlyPropertiesImpl) GetRaw(key string) (string, error) {
+	return GetRaw(r.Properties, key)
+}
+
+func (r *readOnlyPropertiesImpl) Retrive(key string, i interface{}) error {
+	return retrive(r, key, i)
+}
+
+func (r *readOnlyPropertiesImpl) Set(key string, val interface{}) error {
+	return xerrors.Wrapf(ErrReadOnly, "Cannot set property with key '%v'", key)
+}
+
+func (r *readOnlyPropertiesImpl) Unset(key string) error {
+	return xerrors.Wrapf(ErrReadOnly, "Cannot unset property with key '%v'", key)
+}
+
+func (r *readOnlyPropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(r, prefix)
+}
+
+func (r *readOnlyPropertiesImpl) Snapshot() Properties {
+	return r
+}
+
+// Clone return the copy-on-write clone of p, the clone share the values with the snapshot
+// of p, and only the changed values are stored by itself. So the changes of clone will not
+// affect p, and the changes of p after Clone will not affect the clone either. The snapshot
+// shares the values with p until they're changed, so Clone doesn't copy the values.
+func Clone(p Properties) Properties {
+	return &copyOnWritePropertiesImpl{
+		base:      p.Snapshot(),
+		overrides: newTrackedProperties(),
+		removed:   make(map[string]bool),
+	}
+}
+
+type copyOnWritePropertiesImpl struct {
+	base      Properties
+	overrides *trackedPropertiesImpl
+	removed   map[string]bool
+	lock      sync.RWMutex
+	// shared is 1 if the removed is shared with the snapshot, it must been copied
+	// before the first write
+	shared int32
+}
+
+// lookup return the Properties which hold the value of key, or nil if there is no such key
+func (c *copyOnWritePropertiesImpl) lookup(key string) Properties {
+	switch {
+	case c.overrides.Has(key):
+		return c.overrides
+	case !c.removed[key] && c.base.Has(key):
+		return c.base
+	}
+	return nil
+}
+
+func (c *copyOnWritePropertiesImpl) Get(key string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	p := c.lookup(key)
+	if p == nil {
+		return "", xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return p.Get(key)
+}
+
+func (c *copyOnWritePropertiesImpl) GetRaw(key string) (string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	p := c.lookup(key)
+	if p == nil {
+		return "", xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return GetRaw(p, key)
+}
+
+func (c *copyOnWritePropertiesImpl) Retrive(key string, i interface{}) error {
+	return retrive(c, key, i)
+}
+
+func (c *copyOnWritePropertiesImpl) Set(key string, val interface{}) error {
+	origin := callerOrigin()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err := c.overrides.set(key, val, origin)
+	if err != nil {
+		return err
+	}
+	c.own()
+	for k := range c.overrides.propertiesImpl {
+		delete(c.removed, k)
+	}
+	return nil
+}
+
+func (c *copyOnWritePropertiesImpl) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := c.overrides.Keys()
+	for _, k := range c.base.Keys() {
+		if !c.removed[k] && !c.overrides.Has(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *copyOnWritePropertiesImpl) Has(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.lookup(key) != nil
+}
+
+// Unset remove the key from overrides, and hide the key in base.
+func (c *copyOnWritePropertiesImpl) Unset(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.own()
+	found := c.overrides.Unset(key) == nil
+	for _, k := range c.base.Keys() {
+		if IsSubKey(k, key) && !c.removed[k] {
+			c.removed[k] = true
+			found = true
+		}
+	}
+
+	if !found {
+		return xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return nil
+}
+
+func (c *copyOnWritePropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(c, prefix)
+}
+
+func (c *copyOnWritePropertiesImpl) Origin(key string) (Origin, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	p := c.lookup(key)
+	if p == nil {
+		return Origin{}, xerrors.WrapNotFound("property with key='%v' not found", key)
+	}
+	return p.Origin(key)
+}
+
+// Snapshot share the base with the snapshot, which is read-only already, and the overrides
+// and removed keys are copied before the first write of c.
+func (c *copyOnWritePropertiesImpl) Snapshot() Properties {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	atomic.StoreInt32(&c.shared, 1)
+	return newReadOnlyProperties(&copyOnWritePropertiesImpl{
+		base:      c.base,
+		overrides: c.overrides.share(),
+		removed:   c.removed,
+		shared:    1,
+	})
+}
+
+// own copy the removed keys if they are shared, it must been called with the lock held
+func (c *copyOnWritePropertiesImpl) own() {
+	if atomic.LoadInt32(&c.shared) == 0 {
+		return
+	}
+
+	removed := make(map[string]bool, len(c.removed))
+	for k, v := range c.removed {
+		removed[k] = v
+	}
+	c.removed = removed
+	atomic.StoreInt32(&c.shared, 0)
+}
@@ -0,0 +1,139 @@
+package property
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+func TestSnapshot(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	c, err := NewAESGCMCipher([]byte("0123456789abcdef"))
+	g.Expect(err).ToNot(HaveOccurred())
+	ciphertext, err := c.Encrypt("3306")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	path := filepath.Join(dir, "application.yaml")
+	err = ioutil.WriteFile(path, []byte("db:\n  host: localhost\n  port: ENC("+ciphertext+")\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	w, err := WatchFile(path, time.Hour)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer w.Close()
+
+	p := NewCompositeProperties(NewProperties(), NewCompositeProperties(w))
+	p.SetDecryptor(c)
+	err = p.Set("name", "api")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	s := p.Snapshot()
+	err = p.Set("name", "worker")
+	g.Expect(err).ToNot(HaveOccurred())
+	err = ioutil.WriteFile(path, []byte("db:\n  host: remote\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	err = w.Reload()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(p.Has("db.port")).To(BeFalse())
+	g.Expect(s.Keys()).To(Equal([]string{"db.host", "db.port", "name"}))
+	var port int
+	err = s.Retrive("db.port", &port)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(port).To(Equal(3306))
+	v, err := s.Sub("db").Get("host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("localhost"))
+	v, err = s.Get("name")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("api"))
+	origin, err := s.Origin("db.host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(origin).To(Equal(Origin{
+		Source: OriginSourceFile,
+		Name:   path,
+		Line:   2,
+	}))
+
+	err = s.Set("name", "x")
+	g.Expect(xerrors.Is(err, ErrReadOnly)).To(BeTrue())
+	err = s.Sub("db").Unset("host")
+	g.Expect(xerrors.Is(err, ErrReadOnly)).To(BeTrue())
+	g.Expect(s.Snapshot()).To(BeIdenticalTo(s))
+}
+
+func TestClone(t *testing.T) {
+	g := NewWithT(t)
+
+	base := NewProperties()
+	err := base.Set("db", map[string]interface{}{
+		"host": "localhost",
+		"port": 3306,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	c := Clone(base)
+	err = c.Set("db.host", "test")
+	g.Expect(err).ToNot(HaveOccurred())
+	err = c.Unset("db.port")
+	g.Expect(err).ToNot(HaveOccurred())
+	err = c.Set("name", "api")
+	g.Expect(err).ToNot(HaveOccurred())
+	err = base.Set("db.user", "root")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	g.Expect(c.Keys()).To(Equal([]string{"db.host", "name"}))
+	v, err := c.Get("db.host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("test"))
+	_, err = c.Get("db.port")
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+	err = c.Unset("db.port")
+	g.Expect(xerrors.Is(err, xerrors.ErrNotFound)).To(BeTrue())
+
+	g.Expect(base.Keys()).To(Equal([]string{"db.host", "db.port", "db.user"}))
+	v, err = base.Get("db.host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("localhost"))
+
+	// The clone of clone is isolated from it's base too
+	cc := Clone(c)
+	err = cc.Set("db.port", 3307)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cc.Keys()).To(Equal([]string{"db.host", "db.port", "name"}))
+	g.Expect(c.Has("db.port")).To(BeFalse())
+}
+
+func TestSnapshotShareValues(t *testing.T) {
+	g := NewWithT(t)
+
+	p := newTrackedProperties()
+	g.Expect(p.Set("db.host", "localhost")).ToNot(HaveOccurred())
+
+	// The values are shared until the first write
+	s := p.Snapshot()
+	shared := s.(*readOnlyPropertiesImpl).Properties.(*trackedPropertiesImpl)
+	g.Expect(reflect.ValueOf(shared.propertiesImpl).Pointer()).To(Equal(reflect.ValueOf(p.propertiesImpl).Pointer()))
+
+	g.Expect(p.Set("db.host", "test")).ToNot(HaveOccurred())
+	g.Expect(reflect.ValueOf(shared.propertiesImpl).Pointer()).ToNot(Equal(reflect.ValueOf(p.propertiesImpl).Pointer()))
+	v, err := s.Get("db.host")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("localhost"))
+
+	// The next write doesn't copy again
+	values := reflect.ValueOf(p.propertiesImpl).Pointer()
+	g.Expect(p.Unset("db.host")).ToNot(HaveOccurred())
+	g.Expect(reflect.ValueOf(p.propertiesImpl).Pointer()).To(Equal(values))
+	g.Expect(s.Has("db.host")).To(BeTrue())
+}
@@ -0,0 +1,80 @@
+package property
+
+import (
+	"sort"
+	"strings"
+)
+
+// newSubProperties return the view of p re-rooted at prefix
+func newSubProperties(p Properties, prefix string) Properties {
+	return &subPropertiesImpl{
+		p:      p,
+		prefix: strings.TrimSuffix(prefix, "."),
+	}
+}
+
+type subPropertiesImpl struct {
+	p      Properties
+	prefix string
+}
+
+// key return the key of p for key, the empty key is the prefix itself
+func (s *subPropertiesImpl) key(key string) string {
+	if key == "" {
+		return s.prefix
+	}
+	return subKey(s.prefix, key)
+}
+
+func (s *subPropertiesImpl) Get(key string) (string, error) {
+	return s.p.Get(s.key(key))
+}
+
+func (s *subPropertiesImpl) GetRaw(key string) (string, error) {
+	return GetRaw(s.p, s.key(key))
+}
+
+func (s *subPropertiesImpl) Retrive(key string, i interface{}) error {
+	return s.p.Retrive(s.key(key), i)
+}
+
+func (s *subPropertiesImpl) Set(key string, val interface{}) error {
+	return s.p.Set(s.key(key), val)
+}
+
+func (s *subPropertiesImpl) Keys() []string {
+	// The sub Properties with empty prefix is the view of all keys, as subKey does
+	prefix := s.prefix + "."
+	if s.prefix == "" {
+		prefix = ""
+	}
+
+	keys := []string{}
+	for _, k := range s.p.Keys() {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k[len(prefix):])
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *subPropertiesImpl) Has(key string) bool {
+	return s.p.Has(s.key(key))
+}
+
+func (s *subPropertiesImpl) Unset(key string) error {
+	return s.p.Unset(s.key(key))
+}
+
+func (s *subPropertiesImpl) Origin(key string) (Origin, error) {
+	return s.p.Origin(s.key(key))
+}
+
+func (s *subPropertiesImpl) Snapshot() Properties {
+	return newSubProperties(s.p.Snapshot(), s.prefix)
+}
+
+func (s *subPropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(s.p, s.key(prefix))
+}
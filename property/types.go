@@ -13,4 +13,26 @@ type Properties interface {
 	// The val will been transform to string to store with the container, so when
 	//   user try to get the val of key, the string returned.
 	Set(key string, val interface{}) error
+
+	// Keys return all the keys in ascending order.
+	Keys() []string
+
+	// Has return true if the value for key is exists.
+	Has(key string) bool
+
+	// Unset remove the value for key, and the values of it's sub keys, eg: key.sub or key[0].
+	// It will return err if there is no such key.
+	Unset(key string) error
+
+	// Sub return the view of properties re-rooted at prefix, eg: Sub("db").Get("host") is
+	// the same as Get("db.host"). The changes of view will affect the origin properties.
+	Sub(prefix string) Properties
+
+	// Origin return where the value for key comes from, eg: the file path and line,
+	// the env var name or the call site of Set.
+	Origin(key string) (Origin, error)
+
+	// Snapshot return the read-only and consistent view of current values, which will
+	// not been changed by the Set or reload later.
+	Snapshot() Properties
 }
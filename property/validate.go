@@ -0,0 +1,181 @@
+package property
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator is to be implemented by the bound target which need to validate itself,
+// it will been called after the target bound.
+type Validator interface {
+	// Validate return err if the target is invalid, the ValidationErrors returned
+	// will been merged with the key of target as prefix.
+	Validate() error
+}
+
+// ValidationError is the violation of property
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("property '%v' %v", e.Key, e.Message)
+}
+
+// ValidationErrors is the list of violations
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, v := range e {
+		msgs = append(msgs, v.Error())
+	}
+	return fmt.Sprintf("%d validation errors: %v", len(e), strings.Join(msgs, "; "))
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// ValidateValue validate the value with the rules, the rules are separated by comma, eg:
+// "required,min=1,max=65535". The present indicate whether the value is set by property.
+// The supported rules are:
+//  1. required: the value must be present
+//  2. min=n, max=n: the number must be in range [min, max], the length of string/slice/map
+//     must be in range [min, max]
+//  3. oneof=a b c: the value must be one of the values separated by space
+//
+// The rules except required are ignored if the value is not present.
+func ValidateValue(key string, v interface{}, present bool, rules string) ValidationErrors {
+	return validateRules(key, reflect.ValueOf(v), present, rules, IsSecretKey(key))
+}
+
+// validateRules validate the v with rules, the message will not contain the value if it's secret
+func validateRules(key string, v reflect.Value, present bool, rules string, secret bool) ValidationErrors {
+	ret := ValidationErrors{}
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		kv := strings.SplitN(rule, "=", 2)
+		name, param := kv[0], ""
+		if len(kv) == 2 {
+			param = kv[1]
+		}
+
+		if name == "required" {
+			if !present {
+				ret = append(ret, ValidationError{
+					Key:     key,
+					Message: "is required",
+				})
+			}
+			continue
+		}
+		if !present {
+			continue
+		}
+
+		msg := validateRule(v, name, param)
+		if msg != "" && secret {
+			msg = fmt.Sprintf("does not satisfy rule '%v'", rule)
+		}
+		if msg != "" {
+			ret = append(ret, ValidationError{
+				Key:     key,
+				Message: msg,
+			})
+		}
+	}
+	return ret
+}
+
+// validateRule return the violation message, or empty if the rule is satisfied
+func validateRule(v reflect.Value, name string, param string) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid rule '%v=%v'", name, param)
+		}
+
+		var actual float64
+		what := "value"
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			actual = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			actual = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			actual = v.Float()
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			actual = float64(v.Len())
+			what = "length"
+		default:
+			return fmt.Sprintf("cannot apply rule '%v' to type '%v'", name, v.Type())
+		}
+
+		if name == "min" && actual < limit {
+			return fmt.Sprintf("%v must be greater than or equal to %v, got %v", what, param, actual)
+		}
+		if name == "max" && actual > limit {
+			return fmt.Sprintf("%v must be less than or equal to %v, got %v", what, param, actual)
+		}
+	case "oneof":
+		actual := fmt.Sprintf("%v", v.Interface())
+		for _, option := range strings.Fields(param) {
+			if option == actual {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%v], got '%v'", param, actual)
+	default:
+		return fmt.Sprintf("has unknown rule '%v'", name)
+	}
+	return ""
+}
+
+// validateValidator call the Validator of v if it implements
+func validateValidator(key string, v reflect.Value) ValidationErrors {
+	var validator Validator
+	switch {
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Type().Implements(validatorType):
+		validator = v.Interface().(Validator)
+	case v.CanAddr() && v.Addr().Type().Implements(validatorType):
+		validator = v.Addr().Interface().(Validator)
+	case v.IsValid() && v.Kind() != reflect.Ptr && v.Type().Implements(validatorType):
+		validator = v.Interface().(Validator)
+	default:
+		return nil
+	}
+
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+
+	if errs, ok := err.(ValidationErrors); ok {
+		ret := make(ValidationErrors, 0, len(errs))
+		for _, e := range errs {
+			e.Key = subKey(key, e.Key)
+			ret = append(ret, e)
+		}
+		return ret
+	}
+	return ValidationErrors{
+		{
+			Key:     key,
+			Message: err.Error(),
+		},
+	}
+}
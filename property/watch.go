@@ -0,0 +1,202 @@
+package property
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lsytj0413/nuwa/logger"
+	"github.com/lsytj0413/nuwa/xerrors"
+)
+
+// WatchedProperties is the Properties loaded from file, which will been reloaded
+// when the file changed.
+type WatchedProperties interface {
+	Properties
+	Observable
+
+	// Reload load the file immediately, and publish the change events.
+	Reload() error
+
+	// Close stop watching the file.
+	Close()
+}
+
+// WatchFile return the WatchedProperties of file at path, the file is loaded by
+// FromFile, and it will been polled with interval to detect the modification.
+// The errors of reload in background are ignored, and the last loaded values are kept.
+// The file is not polled if interval <= 0, it's only reloaded by Reload.
+func WatchFile(path string, interval time.Duration) (WatchedProperties, error) {
+	w := &watchedPropertiesImpl{
+		path:   path,
+		stopCh: make(chan struct{}),
+	}
+	err := w.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go w.watch(interval)
+	}
+	return w, nil
+}
+
+type watchedPropertiesImpl struct {
+	path    string
+	p       *trackedPropertiesImpl
+	modTime time.Time
+	size    int64
+	lock    sync.RWMutex
+
+	listeners listeners
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+}
+
+func (w *watchedPropertiesImpl) Get(key string) (string, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.p.Get(key)
+}
+
+func (w *watchedPropertiesImpl) Retrive(key string, i interface{}) error {
+	return retrive(w, key, i)
+}
+
+func (w *watchedPropertiesImpl) Set(key string, val interface{}) error {
+	origin := callerOrigin()
+
+	w.lock.Lock()
+	p := w.p.clone()
+	err := p.set(key, val, origin)
+	if err != nil {
+		w.lock.Unlock()
+		return err
+	}
+
+	events := diffProperties(w.p.propertiesImpl, p.propertiesImpl)
+	w.p = p
+	w.lock.Unlock()
+
+	w.listeners.publish(events)
+	return nil
+}
+
+func (w *watchedPropertiesImpl) AddChangeListener(l ChangeListener) func() {
+	return w.listeners.add(l)
+}
+
+func (w *watchedPropertiesImpl) Reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return xerrors.Wrapf(err, "Cannot stat property file '%v'", w.path)
+	}
+
+	p, err := FromFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	old := propertiesImpl{}
+	if w.p != nil {
+		old = w.p.propertiesImpl
+	}
+	events := diffProperties(old, p.(*trackedPropertiesImpl).propertiesImpl)
+	w.p = p.(*trackedPropertiesImpl)
+	w.modTime = info.ModTime()
+	w.size = info.Size()
+	w.lock.Unlock()
+
+	logger.Default().Info("Property file reloaded", "path", w.path, "changes", len(events))
+	w.listeners.publish(events)
+	return nil
+}
+
+func (w *watchedPropertiesImpl) Close() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+func (w *watchedPropertiesImpl) isModified() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+func (w *watchedPropertiesImpl) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			if w.isModified() {
+				err := w.Reload()
+				if err != nil {
+					logger.Default().Warn("Cannot reload property file", "path", w.path, "err", err)
+				}
+			}
+		}
+	}
+}
+
+func (w *watchedPropertiesImpl) Keys() []string {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.p.Keys()
+}
+
+func (w *watchedPropertiesImpl) Has(key string) bool {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.p.Has(key)
+}
+
+func (w *watchedPropertiesImpl) Unset(key string) error {
+	w.lock.Lock()
+	p := w.p.clone()
+	err := p.Unset(key)
+	if err != nil {
+		w.lock.Unlock()
+		return err
+	}
+
+	events := diffProperties(w.p.propertiesImpl, p.propertiesImpl)
+	w.p = p
+	w.lock.Unlock()
+
+	w.listeners.publish(events)
+	return nil
+}
+
+func (w *watchedPropertiesImpl) Origin(key string) (Origin, error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.p.Origin(key)
+}
+
+// Snapshot share the values with w, they are never changed in place
+func (w *watchedPropertiesImpl) Snapshot() Properties {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return newReadOnlyProperties(w.p)
+}
+
+func (w *watchedPropertiesImpl) Sub(prefix string) Properties {
+	return newSubProperties(w, prefix)
+}
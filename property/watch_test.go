@@ -0,0 +1,166 @@
+package property
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWatchFile(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "application.yaml")
+	err = ioutil.WriteFile(path, []byte("log:\n  level: info\nk1: v1\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	w, err := WatchFile(path, 10*time.Millisecond)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer w.Close()
+
+	var lock sync.Mutex
+	events := []ChangeEvent{}
+	w.AddChangeListener(func(es []ChangeEvent) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, es...)
+	})
+
+	err = ioutil.WriteFile(path, []byte("log:\n  level: debug\nk2: v2\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	expect := []ChangeEvent{
+		{
+			Type:     ChangeTypeRemoved,
+			Key:      "k1",
+			OldValue: "v1",
+		},
+		{
+			Type:     ChangeTypeAdded,
+			Key:      "k2",
+			NewValue: "v2",
+		},
+		{
+			Type:     ChangeTypeModified,
+			Key:      "log.level",
+			OldValue: "info",
+			NewValue: "debug",
+		},
+	}
+	g.Eventually(func() []ChangeEvent {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]ChangeEvent{}, events...)
+	}, time.Second, 10*time.Millisecond).Should(ConsistOf(expect))
+
+	v, err := w.Get("log.level")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("debug"))
+}
+
+func TestWatchFileWithoutPolling(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "application.yaml")
+	err := ioutil.WriteFile(path, []byte("k1: v1\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	// The file is not polled with zero interval, it's reloaded by Reload only
+	w, err := WatchFile(path, 0)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer w.Close()
+
+	err = ioutil.WriteFile(path, []byte("k1: v2\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Consistently(func() string {
+		v, _ := w.Get("k1")
+		return v
+	}, 50*time.Millisecond, 10*time.Millisecond).Should(Equal("v1"))
+
+	g.Expect(w.Reload()).ToNot(HaveOccurred())
+	v, err := w.Get("k1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(v).To(Equal("v2"))
+}
+
+func TestCompositeChangeEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "nuwa")
+	g.Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "application.yaml")
+	err = ioutil.WriteFile(path, []byte("k1: v1\nk2: v2\nk3: v3\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	w, err := WatchFile(path, time.Hour)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer w.Close()
+
+	c := NewCompositeProperties(propertiesImpl(map[string]string{
+		"k1": "high",
+	}), w, propertiesImpl(map[string]string{
+		"k3": "low",
+	}))
+	events := []ChangeEvent{}
+	c.AddChangeListener(func(es []ChangeEvent) {
+		events = append(events, es...)
+	})
+
+	err = ioutil.WriteFile(path, []byte("k1: changed\nk2: changed\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	err = w.Reload()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(events).To(Equal([]ChangeEvent{
+		{
+			Type:     ChangeTypeModified,
+			Key:      "k2",
+			OldValue: "v2",
+			NewValue: "changed",
+		},
+		{
+			Type:     ChangeTypeModified,
+			Key:      "k3",
+			OldValue: "v3",
+			NewValue: "low",
+		},
+	}))
+
+	events = []ChangeEvent{}
+	err = c.Set("k4", map[string]interface{}{
+		"a": 1,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(events).To(Equal([]ChangeEvent{
+		{
+			Type:     ChangeTypeAdded,
+			Key:      "k4.a",
+			NewValue: "1",
+		},
+	}))
+
+	// The listeners are not called after they're removed
+	events = []ChangeEvent{}
+	removed := []ChangeEvent{}
+	remove := c.AddChangeListener(func(es []ChangeEvent) {
+		removed = append(removed, es...)
+	})
+	remove()
+	c.Close()
+	err = ioutil.WriteFile(path, []byte("k1: changed\nk2: closed\n"), 0644)
+	g.Expect(err).ToNot(HaveOccurred())
+	err = w.Reload()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(events).To(BeEmpty())
+	g.Expect(removed).To(BeEmpty())
+	g.Expect(w.(*watchedPropertiesImpl).listeners.ls).To(BeEmpty())
+}
@@ -9,4 +9,13 @@ const (
 
 	// ScopePrototype identifier for the standard prototype scope.
 	ScopePrototype Scope = "prototype"
+
+	// ScopeRefresh identifier for the refresh scope, the bean is shared as singleton
+	// until the properties it depends on are changed, then it will been rebuilt.
+	ScopeRefresh Scope = "refresh"
 )
+
+// isSharedScope return true if the bean instance of scope is shared
+func isSharedScope(scope Scope) bool {
+	return scope == ScopeSingleton || scope == ScopeRefresh
+}
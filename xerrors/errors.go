@@ -9,6 +9,9 @@ import (
 // Is alias the errors.Is
 var Is = errors.Is
 
+// As alias the errors.As
+var As = errors.As
+
 // New alias the errors.New
 var New = errors.New
 